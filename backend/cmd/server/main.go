@@ -13,6 +13,8 @@ import (
 	"github.com/tarikozturk017/streak-map/backend/internal/database"
 	"github.com/tarikozturk017/streak-map/backend/internal/handlers"
 	"github.com/tarikozturk017/streak-map/backend/internal/middleware"
+	"github.com/tarikozturk017/streak-map/backend/internal/pomodoro"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
 )
 
 func main() {
@@ -44,6 +46,21 @@ func main() {
 	authHandler := handlers.NewAuthHandler(db.DB, jwtService)
 	goalHandler := handlers.NewGoalHandler(db.DB)
 	progressHandler := handlers.NewProgressHandler(db.DB)
+	challengeService := services.NewChallengeService(db.DB)
+	challengeHandler := handlers.NewChallengeHandler(db.DB, challengeService)
+	metricTypeHandler := handlers.NewMetricTypeHandler(db.DB)
+	checklistHandler := handlers.NewChecklistHandler(db.DB)
+	templateHandler := handlers.NewTemplateHandler(db.DB)
+	tagHandler := handlers.NewTagHandler(db.DB)
+	freezeService := services.NewFreezeService(db.DB)
+	freezeHandler := handlers.NewFreezeHandler(db.DB, freezeService)
+	heatmapHandler := handlers.NewHeatmapHandler(db.DB)
+	shareHandler := handlers.NewShareHandler(db.DB)
+	summaryHandler := handlers.NewSummaryHandler(db.DB, services.NewValidationService(db.DB))
+	analyticsHandler := handlers.NewAnalyticsHandler(db.DB)
+	timerHandler := handlers.NewTimerHandler(db.DB, services.NewTimerService(db.DB))
+	pomodoroService := services.NewPomodoroService(db.DB)
+	pomodoroHandler := handlers.NewPomodoroHandler(db.DB, pomodoro.NewEngine(pomodoroService), pomodoroService)
 	authMiddleware := middleware.AuthMiddleware(jwtService)
 
 	mux := http.NewServeMux()
@@ -52,6 +69,7 @@ func main() {
 	mux.HandleFunc("POST /auth/register", authHandler.Register)
 	mux.HandleFunc("POST /auth/login", authHandler.Login)
 	mux.Handle("GET /auth/me", authMiddleware(http.HandlerFunc(authHandler.Me)))
+	mux.Handle("PUT /auth/me", authMiddleware(http.HandlerFunc(authHandler.UpdateMe)))
 
 	// Goal routes
 	mux.Handle("POST /goals", authMiddleware(http.HandlerFunc(goalHandler.CreateGoal)))
@@ -59,11 +77,64 @@ func main() {
 	mux.Handle("GET /goals/{id}", authMiddleware(http.HandlerFunc(goalHandler.GetGoal)))
 	mux.Handle("PUT /goals/{id}", authMiddleware(http.HandlerFunc(goalHandler.UpdateGoal)))
 	mux.Handle("DELETE /goals/{id}", authMiddleware(http.HandlerFunc(goalHandler.DeleteGoal)))
+	mux.Handle("GET /goals/{id}/milestone", authMiddleware(http.HandlerFunc(goalHandler.GetGoalMilestone)))
+	mux.Handle("GET /goals/{id}/challenge", authMiddleware(http.HandlerFunc(challengeHandler.GetChallengeStatus)))
+	mux.Handle("GET /goals/{id}/summary", authMiddleware(http.HandlerFunc(summaryHandler.GetGoalSummary)))
+	mux.Handle("GET /summary", authMiddleware(http.HandlerFunc(summaryHandler.GetSummary)))
+	mux.Handle("GET /challenges", authMiddleware(http.HandlerFunc(challengeHandler.GetChallenges)))
+
+	// Checklist routes
+	mux.Handle("POST /goals/{id}/checklist-items", authMiddleware(http.HandlerFunc(checklistHandler.CreateChecklistItem)))
+	mux.Handle("GET /goals/{id}/checklist-items", authMiddleware(http.HandlerFunc(checklistHandler.GetChecklistItems)))
+	mux.Handle("PUT /goals/{id}/checklist-items/order", authMiddleware(http.HandlerFunc(checklistHandler.ReorderChecklistItems)))
+	mux.Handle("PUT /goals/{id}/checklist-items/{itemId}", authMiddleware(http.HandlerFunc(checklistHandler.UpdateChecklistItem)))
+
+	// Timer routes
+	mux.Handle("POST /goals/{id}/timer/start", authMiddleware(http.HandlerFunc(timerHandler.StartTimer)))
+	mux.Handle("POST /goals/{id}/timer/pause", authMiddleware(http.HandlerFunc(timerHandler.PauseTimer)))
+	mux.Handle("POST /goals/{id}/timer/resume", authMiddleware(http.HandlerFunc(timerHandler.ResumeTimer)))
+	mux.Handle("POST /goals/{id}/timer/stop", authMiddleware(http.HandlerFunc(timerHandler.StopTimer)))
+	mux.Handle("GET /timers/active", authMiddleware(http.HandlerFunc(timerHandler.GetActiveTimer)))
+
+	// Pomodoro routes
+	mux.Handle("POST /goals/{id}/pomodoro/start", authMiddleware(http.HandlerFunc(pomodoroHandler.StartPomodoro)))
+	mux.Handle("GET /pomodoro", authMiddleware(http.HandlerFunc(pomodoroHandler.GetPomodoro)))
+	mux.Handle("POST /pomodoro/skip", authMiddleware(http.HandlerFunc(pomodoroHandler.SkipPhase)))
+	mux.Handle("POST /pomodoro/stop", authMiddleware(http.HandlerFunc(pomodoroHandler.StopPomodoro)))
+	mux.Handle("GET /pomodoro/events", authMiddleware(http.HandlerFunc(pomodoroHandler.StreamPomodoroEvents)))
+	mux.Handle("GET /pomodoro/stats", authMiddleware(http.HandlerFunc(pomodoroHandler.GetPomodoroStats)))
+
+	// Template routes
+	mux.Handle("GET /templates", authMiddleware(http.HandlerFunc(templateHandler.GetTemplates)))
+	mux.Handle("POST /templates", authMiddleware(http.HandlerFunc(templateHandler.ImportTemplate)))
+	mux.Handle("GET /templates/{ref}", authMiddleware(http.HandlerFunc(templateHandler.ExportTemplate)))
+	mux.Handle("DELETE /templates/{ref}", authMiddleware(http.HandlerFunc(templateHandler.DeleteTemplate)))
+	mux.Handle("POST /goals/{id}/template", authMiddleware(http.HandlerFunc(templateHandler.SaveGoalAsTemplate)))
+	mux.Handle("POST /goals/from-template", authMiddleware(http.HandlerFunc(templateHandler.CreateGoalFromTemplate)))
+
+	// Tag routes
+	mux.Handle("POST /tags", authMiddleware(http.HandlerFunc(tagHandler.CreateTag)))
+	mux.Handle("GET /tags", authMiddleware(http.HandlerFunc(tagHandler.GetTags)))
+	mux.Handle("GET /tags/summary", authMiddleware(http.HandlerFunc(tagHandler.GetTagSummaries)))
+	mux.Handle("PUT /tags/{id}", authMiddleware(http.HandlerFunc(tagHandler.UpdateTag)))
+	mux.Handle("DELETE /tags/{id}", authMiddleware(http.HandlerFunc(tagHandler.DeleteTag)))
+
+	// Streak protection routes
+	mux.Handle("GET /freezes", authMiddleware(http.HandlerFunc(freezeHandler.GetFreezes)))
+	mux.Handle("POST /excused-periods", authMiddleware(http.HandlerFunc(freezeHandler.CreateExcusedPeriod)))
+	mux.Handle("GET /excused-periods", authMiddleware(http.HandlerFunc(freezeHandler.GetExcusedPeriods)))
+	mux.Handle("DELETE /excused-periods/{id}", authMiddleware(http.HandlerFunc(freezeHandler.DeleteExcusedPeriod)))
 
 	// Goal group routes
 	mux.Handle("POST /goal-groups", authMiddleware(http.HandlerFunc(goalHandler.CreateGoalGroup)))
 	mux.Handle("GET /goal-groups", authMiddleware(http.HandlerFunc(goalHandler.GetGoalGroups)))
 
+	// Metric type routes
+	mux.Handle("POST /metric-types", authMiddleware(http.HandlerFunc(metricTypeHandler.CreateMetricType)))
+	mux.Handle("GET /metric-types", authMiddleware(http.HandlerFunc(metricTypeHandler.GetMetricTypes)))
+	mux.Handle("PUT /metric-types/{id}", authMiddleware(http.HandlerFunc(metricTypeHandler.UpdateMetricType)))
+	mux.Handle("DELETE /metric-types/{id}", authMiddleware(http.HandlerFunc(metricTypeHandler.DeleteMetricType)))
+
 	// Progress routes
 	mux.Handle("POST /progress", authMiddleware(http.HandlerFunc(progressHandler.CreateProgress)))
 	mux.Handle("POST /progress/time", authMiddleware(http.HandlerFunc(progressHandler.CreateTimeProgress)))
@@ -72,6 +143,23 @@ func main() {
 	mux.Handle("PUT /progress/{id}", authMiddleware(http.HandlerFunc(progressHandler.UpdateProgress)))
 	mux.Handle("DELETE /progress/{id}", authMiddleware(http.HandlerFunc(progressHandler.DeleteProgress)))
 	mux.Handle("GET /heatmap", authMiddleware(http.HandlerFunc(progressHandler.GetHeatmapData)))
+	mux.Handle("GET /heatmap.svg", authMiddleware(http.HandlerFunc(heatmapHandler.GetHeatmapSVG)))
+	mux.Handle("GET /heatmap.png", authMiddleware(http.HandlerFunc(heatmapHandler.GetHeatmapPNG)))
+
+	// Analytics routes
+	mux.Handle("GET /analytics/trends", authMiddleware(http.HandlerFunc(analyticsHandler.GetTrends)))
+	mux.Handle("GET /analytics/insights", authMiddleware(http.HandlerFunc(analyticsHandler.GetInsights)))
+	mux.Handle("GET /analytics/correlations", authMiddleware(http.HandlerFunc(analyticsHandler.GetCorrelations)))
+
+	// Share link routes
+	mux.Handle("POST /shares", authMiddleware(http.HandlerFunc(shareHandler.CreateShare)))
+	mux.Handle("GET /shares", authMiddleware(http.HandlerFunc(shareHandler.GetShares)))
+	mux.Handle("PUT /shares/{id}", authMiddleware(http.HandlerFunc(shareHandler.UpdateShare)))
+	mux.Handle("DELETE /shares/{id}", authMiddleware(http.HandlerFunc(shareHandler.DeleteShare)))
+
+	// Public routes, reached through a share token instead of a session
+	mux.HandleFunc("GET /public/h/{file}", shareHandler.GetPublicHeatmap)
+	mux.HandleFunc("GET /public/h/{token}/badge", shareHandler.GetPublicBadge)
 
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -86,6 +174,21 @@ func main() {
 		Handler: mux,
 	}
 
+	// Finished challenges are closed out and missed days frozen even if nobody
+	// asks for their status
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if err := challengeService.FinalizeExpired(time.Now()); err != nil {
+				log.Printf("Failed to finalize expired challenges: %v", err)
+			}
+			if err := freezeService.SweepAll(time.Now()); err != nil {
+				log.Printf("Failed to apply streak freezes: %v", err)
+			}
+		}
+	}()
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("listen: %s\n", err)
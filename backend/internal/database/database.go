@@ -30,12 +30,27 @@ func (db *DB) AutoMigrate() error {
 		&models.User{},
 		&models.RefreshToken{},
 		&models.GoalGroup{},
+		&models.MetricType{},
+		&models.Tag{},
 		&models.Goal{},
 		&models.Progress{},
+		&models.ChallengeResult{},
+		&models.TimerSession{},
+		&models.TimerSegment{},
+		&models.PomodoroInterval{},
+		&models.ChecklistItem{},
+		&models.ChecklistCheck{},
+		&models.GoalTemplate{},
+		&models.FreezeToken{},
+		&models.ExcusedPeriod{},
+		&models.ShareLink{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate: %w", err)
 	}
+	if err := db.migrateData(); err != nil {
+		return fmt.Errorf("failed to migrate data: %w", err)
+	}
 	
 	log.Println("Database migration completed successfully")
 	return nil
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
+)
+
+// schemaMigration records a data migration that has been applied
+type schemaMigration struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// dataMigrations rewrite existing rows after a schema change. Each runs once,
+// in its own transaction, in order.
+var dataMigrations = []struct {
+	name string
+	run  func(tx *gorm.DB) error
+}{
+	{"base_units", migrateBaseUnits},
+	{"progress_logged_at", migrateLoggedAt},
+}
+
+func (db *DB) migrateData() error {
+	if err := db.DB.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+	for _, migration := range dataMigrations {
+		err := db.DB.Transaction(func(tx *gorm.DB) error {
+			var applied int64
+			if err := tx.Model(&schemaMigration{}).Where("name = ?", migration.name).Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied > 0 {
+				return nil
+			}
+			if err := migration.run(tx); err != nil {
+				return err
+			}
+			log.Printf("Applied data migration %s", migration.name)
+			return tx.Create(&schemaMigration{Name: migration.name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("data migration %s: %w", migration.name, err)
+		}
+	}
+	return nil
+}
+
+// migrateBaseUnits moves distance goals onto base units. Their targets and
+// progress used to be stored as given, in the goal's own unit (5 for a 5 km
+// goal), and are now stored in meters. Time goals always stored minutes, so
+// only their unit names change. Unit names are made canonical and completion
+// rates recomputed.
+func migrateBaseUnits(tx *gorm.DB) error {
+	var goals []models.Goal
+	if err := tx.Where("type IN ?", []models.GoalType{models.GoalTypeTime, models.GoalTypeDistance}).
+		Preload("MetricType").
+		Find(&goals).Error; err != nil {
+		return err
+	}
+
+	for i := range goals {
+		goal := &goals[i]
+		factor, ok := rescaleToBaseUnit(goal)
+		if !ok {
+			continue
+		}
+		if err := tx.Model(goal).UpdateColumns(map[string]interface{}{
+			"unit":       goal.Unit,
+			"target":     goal.Target,
+			"target_max": goal.TargetMax,
+		}).Error; err != nil {
+			return err
+		}
+		if factor == 1 {
+			continue
+		}
+
+		if err := tx.Model(&models.Progress{}).Where("goal_id = ?", goal.ID).
+			UpdateColumn("value", gorm.Expr("value * ?", factor)).Error; err != nil {
+			return err
+		}
+		var entries []models.Progress
+		if err := tx.Where("goal_id = ?", goal.ID).Find(&entries).Error; err != nil {
+			return err
+		}
+		for j := range entries {
+			entries[j].CalculateCompletionRate(goal)
+			if err := tx.Model(&entries[j]).UpdateColumn("completion_rate", entries[j].CompletionRate).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rescaleToBaseUnit makes a legacy goal's unit canonical and its targets base
+// units, returning the factor its progress values need. Goals without a unit,
+// or with one unknown for their type, already read as base units and are
+// skipped.
+func rescaleToBaseUnit(goal *models.Goal) (float64, bool) {
+	if goal.Unit == "" {
+		return 0, false
+	}
+	unit, err := units.Lookup(goal.Unit)
+	if err != nil || unit.Dimension != goal.Type.Dimension() {
+		return 0, false
+	}
+
+	goal.Unit = unit.Name
+	factor := unit.ToBase
+	if goal.Type == models.GoalTypeTime {
+		factor = 1 // Stored in minutes whatever the label
+	}
+	goal.Target *= factor
+	if goal.TargetMax != nil {
+		targetMax := *goal.TargetMax * factor
+		goal.TargetMax = &targetMax
+	}
+	return factor, true
+}
+
+// migrateLoggedAt replaces the migration time that adding logged_at stamped on
+// existing entries with when they were logged: their creation time, or the
+// start of the tracked day for entries logged on a later day
+func migrateLoggedAt(tx *gorm.DB) error {
+	return tx.Exec(`UPDATE progress SET logged_at = CASE
+		WHEN created_at::date = tracked_date::date THEN created_at
+		ELSE tracked_date
+	END`).Error
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+func TestRescaleToBaseUnit(t *testing.T) {
+	cases := []struct {
+		name          string
+		goal          models.Goal
+		wantOK        bool
+		wantUnit      string
+		wantTarget    float64
+		wantTargetMax float64
+		wantFactor    float64
+	}{
+		// Time goals stored minutes even when labelled hours
+		{"hours", models.Goal{Type: models.GoalTypeTime, Unit: "hours", Target: 120, TargetMax: ptr(180)}, true, "hours", 120, 180, 1},
+		{"hour alias", models.Goal{Type: models.GoalTypeTime, Unit: "hr", Target: 90}, true, "hours", 90, 0, 1},
+		{"minutes", models.Goal{Type: models.GoalTypeTime, Unit: "minutes", Target: 30}, true, "minutes", 30, 0, 1},
+		// Distance goals stored the labelled unit
+		{"kilometers", models.Goal{Type: models.GoalTypeDistance, Unit: "km", Target: 5, TargetMax: ptr(10)}, true, "km", 5000, 10000, 1000},
+		{"meters", models.Goal{Type: models.GoalTypeDistance, Unit: "meters", Target: 800}, true, "meters", 800, 0, 1},
+		{"no unit", models.Goal{Type: models.GoalTypeDistance, Target: 5}, false, "", 5, 0, 0},
+		{"wrong dimension", models.Goal{Type: models.GoalTypeTime, Unit: "km", Target: 5}, false, "km", 5, 0, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			goal := c.goal
+			factor, ok := rescaleToBaseUnit(&goal)
+			if ok != c.wantOK || factor != c.wantFactor {
+				t.Fatalf("rescaleToBaseUnit() = %v, %t, want %v, %t", factor, ok, c.wantFactor, c.wantOK)
+			}
+			if goal.Unit != c.wantUnit || goal.Target != c.wantTarget {
+				t.Errorf("goal = %s %v, want %s %v", goal.Unit, goal.Target, c.wantUnit, c.wantTarget)
+			}
+			if goal.TargetMax != nil && *goal.TargetMax != c.wantTargetMax {
+				t.Errorf("target_max = %v, want %v", *goal.TargetMax, c.wantTargetMax)
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/heatmap"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type AnalyticsHandler struct {
+	db           *gorm.DB
+	correlations *services.CorrelationService
+}
+
+func NewAnalyticsHandler(db *gorm.DB) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		db:           db,
+		correlations: services.NewCorrelationService(db, 256),
+	}
+}
+
+// GetTrends returns each goal's daily completion and value with 7- and 30-day
+// moving averages, and their week-over-week and month-over-month change. It
+// takes the heatmap's range and goal filters. period_a_start, period_a_end,
+// period_b_start and period_b_end, given together, also compare two periods.
+func (h *AnalyticsHandler) GetTrends(w http.ResponseWriter, r *http.Request) {
+	filter, ok := heatmapFilterFromRequest(h.db, w, r)
+	if !ok {
+		return
+	}
+	if filter.StartDate.Before(filter.EndDate.AddDate(0, 0, -(heatmap.MaxDays - 1))) {
+		http.Error(w, "Date range is too long", http.StatusBadRequest)
+		return
+	}
+
+	var a, b *models.TrendPeriod
+	query := r.URL.Query()
+	if query.Has("period_a_start") || query.Has("period_a_end") || query.Has("period_b_start") || query.Has("period_b_end") {
+		if a, ok = trendPeriodFromRequest(w, r, "period_a"); !ok {
+			return
+		}
+		if b, ok = trendPeriodFromRequest(w, r, "period_b"); !ok {
+			return
+		}
+	}
+
+	loc := locationFor(h.db, filter.UserID)
+	trends, err := services.Trends(h.db, filter, a, b, models.LocalDate(time.Now(), loc))
+	if err != nil {
+		http.Error(w, "Failed to compute trends", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trends)
+}
+
+// GetInsights returns how consistent the user is on each weekday and in each
+// month, at what hours they log, and findings such as the weekday a goal is
+// missed most. It takes the heatmap's range and goal filters.
+func (h *AnalyticsHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
+	filter, ok := heatmapFilterFromRequest(h.db, w, r)
+	if !ok {
+		return
+	}
+	if filter.StartDate.Before(filter.EndDate.AddDate(0, 0, -(heatmap.MaxDays - 1))) {
+		http.Error(w, "Date range is too long", http.StatusBadRequest)
+		return
+	}
+
+	insights, err := services.Insights(h.db, filter, locationFor(h.db, filter.UserID))
+	if err != nil {
+		http.Error(w, "Failed to compute insights", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(insights)
+}
+
+// GetCorrelations returns Pearson correlations between the daily completion of
+// every pair of goals, with p-values and 95% confidence intervals. lag pairs
+// each day with the other goal's day that many days later, and min_overlap
+// sets how many shared days a pair needs. It takes the heatmap's range and
+// goal filters. Results are cached, and their fingerprint is the ETag.
+func (h *AnalyticsHandler) GetCorrelations(w http.ResponseWriter, r *http.Request) {
+	filter, ok := heatmapFilterFromRequest(h.db, w, r)
+	if !ok {
+		return
+	}
+	if filter.StartDate.Before(filter.EndDate.AddDate(0, 0, -(heatmap.MaxDays - 1))) {
+		http.Error(w, "Date range is too long", http.StatusBadRequest)
+		return
+	}
+
+	var opts models.CorrelationOptions
+	var err error
+	if lag := r.URL.Query().Get("lag"); lag != "" {
+		if opts.Lag, err = strconv.Atoi(lag); err != nil {
+			http.Error(w, "Invalid lag", http.StatusBadRequest)
+			return
+		}
+	}
+	if minOverlap := r.URL.Query().Get("min_overlap"); minOverlap != "" {
+		if opts.MinOverlap, err = strconv.Atoi(minOverlap); err != nil || opts.MinOverlap == 0 {
+			http.Error(w, "Invalid min_overlap", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	correlations, fingerprint, err := h.correlations.Correlations(filter, opts, locationFor(h.db, filter.UserID))
+	if err != nil {
+		http.Error(w, "Failed to compute correlations", http.StatusInternalServerError)
+		return
+	}
+
+	etag := `"` + fingerprint + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(correlations)
+}
+
+// trendPeriodFromRequest reads the required <name>_start and <name>_end dates.
+// It writes the error response itself.
+func trendPeriodFromRequest(w http.ResponseWriter, r *http.Request, name string) (*models.TrendPeriod, bool) {
+	start, err := time.Parse("2006-01-02", r.URL.Query().Get(name+"_start"))
+	if err != nil {
+		http.Error(w, "Invalid or missing "+name+"_start", http.StatusBadRequest)
+		return nil, false
+	}
+	end, err := time.Parse("2006-01-02", r.URL.Query().Get(name+"_end"))
+	if err != nil {
+		http.Error(w, "Invalid or missing "+name+"_end", http.StatusBadRequest)
+		return nil, false
+	}
+	if end.Before(start) {
+		http.Error(w, name+"_end must not be before "+name+"_start", http.StatusBadRequest)
+		return nil, false
+	}
+	if start.Before(end.AddDate(0, 0, -(heatmap.MaxDays - 1))) {
+		http.Error(w, name+" is too long", http.StatusBadRequest)
+		return nil, false
+	}
+	return &models.TrendPeriod{Start: start, End: end}, true
+}
@@ -10,6 +10,8 @@ import (
 	"gorm.io/gorm"
 	"github.com/tarikozturk017/streak-map/backend/internal/auth"
 	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/palette"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
 )
 
 type AuthHandler struct {
@@ -173,4 +175,87 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
-}
\ No newline at end of file
+}
+func (h *AuthHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var req models.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.UnitSystem != nil {
+		if !req.UnitSystem.IsValid() {
+			http.Error(w, "Invalid unit system", http.StatusBadRequest)
+			return
+		}
+		user.UnitSystem = *req.UnitSystem
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); *req.Timezone == "" || err != nil {
+			http.Error(w, "Invalid timezone", http.StatusBadRequest)
+			return
+		}
+		user.Timezone = *req.Timezone
+	}
+	if req.HeatmapPalette != nil {
+		if req.HeatmapPalette.IsEmpty() {
+			user.HeatmapPalette = nil
+		} else if err := req.HeatmapPalette.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else {
+			user.HeatmapPalette = req.HeatmapPalette
+		}
+	}
+
+	user.UpdatedAt = time.Now()
+
+	if err := h.db.Save(&user).Error; err != nil {
+		http.Error(w, "Failed to update user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// unitSystemFor returns the user's preferred unit system, falling back to metric
+func unitSystemFor(db *gorm.DB, userID uuid.UUID) units.System {
+	var user models.User
+	if err := db.Select("unit_system").First(&user, userID).Error; err != nil || !user.UnitSystem.IsValid() {
+		return units.SystemMetric
+	}
+	return user.UnitSystem
+}
+
+// heatmapPaletteFor returns the user's default heatmap palette, nil when unset
+func heatmapPaletteFor(db *gorm.DB, userID uuid.UUID) *palette.Palette {
+	var user models.User
+	if err := db.Select("heatmap_palette").First(&user, userID).Error; err != nil {
+		return nil
+	}
+	return user.HeatmapPalette
+}
+
+// locationFor returns the user's time zone, UTC when it can't be loaded
+func locationFor(db *gorm.DB, userID uuid.UUID) *time.Location {
+	var user models.User
+	if err := db.Select("timezone").First(&user, userID).Error; err != nil {
+		return time.UTC
+	}
+	return user.Location()
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type ChallengeHandler struct {
+	db         *gorm.DB
+	challenges *services.ChallengeService
+}
+
+func NewChallengeHandler(db *gorm.DB, challenges *services.ChallengeService) *ChallengeHandler {
+	return &ChallengeHandler{
+		db:         db,
+		challenges: challenges,
+	}
+}
+
+func (h *ChallengeHandler) GetChallengeStatus(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	goalID := r.PathValue("id")
+
+	parsedGoalID, err := uuid.Parse(goalID)
+	if err != nil {
+		http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+		return
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", parsedGoalID, userID).First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
+		return
+	}
+
+	if !goal.IsChallenge() {
+		http.Error(w, "Goal is not a challenge", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.challenges.Status(&goal, models.LocalDate(time.Now(), locationFor(h.db, userID)))
+	if err != nil {
+		http.Error(w, "Failed to evaluate challenge", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func (h *ChallengeHandler) GetChallenges(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var goals []models.Goal
+	if err := h.db.Where("user_id = ? AND mode = ?", userID, models.GoalModeChallenge).
+		Order("start_date DESC, created_at DESC").
+		Find(&goals).Error; err != nil {
+		http.Error(w, "Failed to fetch challenges", http.StatusInternalServerError)
+		return
+	}
+
+	today := models.LocalDate(time.Now(), locationFor(h.db, userID))
+	statuses := make([]*models.ChallengeStatus, 0, len(goals))
+	for i := range goals {
+		status, err := h.challenges.Status(&goals[i], today)
+		if err != nil {
+			http.Error(w, "Failed to evaluate challenge", http.StatusInternalServerError)
+			return
+		}
+		statuses = append(statuses, status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+type ChecklistHandler struct {
+	db *gorm.DB
+}
+
+func NewChecklistHandler(db *gorm.DB) *ChecklistHandler {
+	return &ChecklistHandler{db: db}
+}
+
+func (h *ChecklistHandler) CreateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.checklistGoal(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		http.Error(w, "Checklist item title is required", http.StatusBadRequest)
+		return
+	}
+
+	var position int
+	h.db.Model(&models.ChecklistItem{}).Where("goal_id = ?", goal.ID).
+		Select("COALESCE(MAX(position), -1) + 1").Scan(&position)
+
+	item := models.ChecklistItem{
+		ID:        uuid.New(),
+		GoalID:    goal.ID,
+		Title:     strings.TrimSpace(req.Title),
+		Required:  req.Required,
+		Position:  position,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return refreshChecklist(tx, goal)
+	})
+	if err != nil {
+		http.Error(w, "Failed to create checklist item", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+func (h *ChecklistHandler) GetChecklistItems(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.checklistGoal(w, r)
+	if !ok {
+		return
+	}
+
+	query := h.db.Where("goal_id = ?", goal.ID)
+	if r.URL.Query().Get("include_archived") != "true" {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	items := []models.ChecklistItem{}
+	if err := query.Order("position ASC, created_at ASC").Find(&items).Error; err != nil {
+		http.Error(w, "Failed to fetch checklist items", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// UpdateChecklistItem renames, marks required or archives an item. Archiving keeps
+// the item's past check-offs; it only stops counting from today on.
+func (h *ChecklistHandler) UpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.checklistGoal(w, r)
+	if !ok {
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("itemId"))
+	if err != nil {
+		http.Error(w, "Invalid checklist item ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var item models.ChecklistItem
+	if err := h.db.Where("id = ? AND goal_id = ?", itemID, goal.ID).First(&item).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Checklist item not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch checklist item", http.StatusInternalServerError)
+		return
+	}
+
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			http.Error(w, "Checklist item title is required", http.StatusBadRequest)
+			return
+		}
+		item.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Required != nil {
+		item.Required = *req.Required
+	}
+	if req.Archived != nil {
+		switch {
+		case *req.Archived && item.ArchivedAt == nil:
+			now := time.Now()
+			item.ArchivedAt = &now
+		case !*req.Archived:
+			item.ArchivedAt = nil
+		}
+	}
+	item.UpdatedAt = time.Now()
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return refreshChecklist(tx, goal)
+	})
+	if err != nil {
+		http.Error(w, "Failed to update checklist item", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// ReorderChecklistItems sets item positions from the given order. Items left out
+// (e.g., archived ones) keep their relative order after the listed ones.
+func (h *ChecklistHandler) ReorderChecklistItems(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.checklistGoal(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReorderChecklistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var items []models.ChecklistItem
+	if err := h.db.Where("goal_id = ?", goal.ID).Order("position ASC, created_at ASC").Find(&items).Error; err != nil {
+		http.Error(w, "Failed to fetch checklist items", http.StatusInternalServerError)
+		return
+	}
+
+	byID := make(map[uuid.UUID]*models.ChecklistItem, len(items))
+	for i := range items {
+		byID[items[i].ID] = &items[i]
+	}
+
+	ordered := make([]*models.ChecklistItem, 0, len(items))
+	seen := make(map[uuid.UUID]bool, len(req.ItemIDs))
+	for _, id := range req.ItemIDs {
+		item, found := byID[id]
+		if !found {
+			http.Error(w, fmt.Sprintf("Checklist item %s not found", id), http.StatusBadRequest)
+			return
+		}
+		if seen[id] {
+			http.Error(w, fmt.Sprintf("Checklist item %s listed twice", id), http.StatusBadRequest)
+			return
+		}
+		seen[id] = true
+		ordered = append(ordered, item)
+	}
+	for i := range items {
+		if !seen[items[i].ID] {
+			ordered = append(ordered, &items[i])
+		}
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for position, item := range ordered {
+			item.Position = position
+			if err := tx.Model(&models.ChecklistItem{}).Where("id = ?", item.ID).
+				Updates(map[string]interface{}{"position": position, "updated_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "Failed to reorder checklist items", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]models.ChecklistItem, 0, len(ordered))
+	for _, item := range ordered {
+		result = append(result, *item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *ChecklistHandler) checklistGoal(w http.ResponseWriter, r *http.Request) (*models.Goal, bool) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	parsedGoalID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", parsedGoalID, userID).First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return nil, false
+		}
+		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !goal.IsChecklist() {
+		http.Error(w, "Goal is not a checklist goal", http.StatusBadRequest)
+		return nil, false
+	}
+	return &goal, true
+}
+
+// refreshChecklist brings the goal's target and existing completion rates in line
+// with its current items
+func refreshChecklist(tx *gorm.DB, goal *models.Goal) error {
+	goal.ChecklistItems = nil
+	if err := tx.Where("goal_id = ?", goal.ID).Order("position ASC, created_at ASC").
+		Find(&goal.ChecklistItems).Error; err != nil {
+		return err
+	}
+	goal.SyncChecklistTarget()
+	if err := tx.Model(&models.Goal{}).Where("id = ?", goal.ID).
+		Updates(map[string]interface{}{"target": goal.Target, "updated_at": time.Now()}).Error; err != nil {
+		return err
+	}
+	return recalculateCompletionRates(tx, goal)
+}
+
+// checklistChecks turns the item IDs of an entry into check-offs, rejecting items
+// that aren't on the goal's list for that day
+func checklistChecks(goal *models.Goal, itemIDs []uuid.UUID, date time.Time) ([]models.ChecklistCheck, error) {
+	items := make(map[uuid.UUID]*models.ChecklistItem, len(goal.ChecklistItems))
+	for i := range goal.ChecklistItems {
+		items[goal.ChecklistItems[i].ID] = &goal.ChecklistItems[i]
+	}
+
+	checks := make([]models.ChecklistCheck, 0, len(itemIDs))
+	seen := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		if seen[id] {
+			continue
+		}
+		item, found := items[id]
+		if !found {
+			return nil, fmt.Errorf("checklist item %s not found", id)
+		}
+		if !goal.ItemCountsOn(item, date) {
+			return nil, fmt.Errorf("checklist item %q is not on the list for that day", item.Title)
+		}
+		seen[id] = true
+		checks = append(checks, models.ChecklistCheck{ID: uuid.New(), ItemID: id, CreatedAt: time.Now()})
+	}
+	return checks, nil
+}
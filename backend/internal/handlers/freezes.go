@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type FreezeHandler struct {
+	db      *gorm.DB
+	freezes *services.FreezeService
+}
+
+func NewFreezeHandler(db *gorm.DB, freezes *services.FreezeService) *FreezeHandler {
+	return &FreezeHandler{
+		db:      db,
+		freezes: freezes,
+	}
+}
+
+func (h *FreezeHandler) GetFreezes(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	balance, err := h.freezes.Balance(userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch freeze tokens", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+func (h *FreezeHandler) CreateExcusedPeriod(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var req models.CreateExcusedPeriodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.GoalIDs) > 0 {
+		var goalCount int64
+		if err := h.db.Model(&models.Goal{}).
+			Where("id IN ? AND user_id = ?", req.GoalIDs, userID).
+			Count(&goalCount).Error; err != nil {
+			http.Error(w, "Failed to fetch goals", http.StatusInternalServerError)
+			return
+		}
+		if int(goalCount) != len(req.GoalIDs) {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	// One period per chosen goal, or a single one covering every goal
+	var periods []models.ExcusedPeriod
+	newPeriod := func(goalID *uuid.UUID) models.ExcusedPeriod {
+		return models.ExcusedPeriod{
+			ID:        uuid.New(),
+			UserID:    userID,
+			GoalID:    goalID,
+			StartDate: req.StartDate,
+			EndDate:   req.EndDate,
+			Reason:    req.Reason,
+			CreatedAt: time.Now(),
+		}
+	}
+	if len(req.GoalIDs) == 0 {
+		periods = append(periods, newPeriod(nil))
+	}
+	for i := range req.GoalIDs {
+		periods = append(periods, newPeriod(&req.GoalIDs[i]))
+	}
+
+	if err := h.db.Create(&periods).Error; err != nil {
+		http.Error(w, "Failed to create excused period", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(periods)
+}
+
+func (h *FreezeHandler) GetExcusedPeriods(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	query := h.db.Where("user_id = ?", userID)
+
+	if goalID := r.URL.Query().Get("goal_id"); goalID != "" {
+		if parsedGoalID, err := uuid.Parse(goalID); err == nil {
+			query = query.Where("goal_id = ? OR goal_id IS NULL", parsedGoalID)
+		}
+	}
+
+	periods := []models.ExcusedPeriod{}
+	if err := query.Order("start_date DESC").Find(&periods).Error; err != nil {
+		http.Error(w, "Failed to fetch excused periods", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(periods)
+}
+
+func (h *FreezeHandler) DeleteExcusedPeriod(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	periodID := r.PathValue("id")
+
+	parsedPeriodID, err := uuid.Parse(periodID)
+	if err != nil {
+		http.Error(w, "Invalid excused period ID", http.StatusBadRequest)
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", parsedPeriodID, userID).Delete(&models.ExcusedPeriod{})
+	if result.Error != nil {
+		http.Error(w, "Failed to delete excused period", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Excused period not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
 )
 
 type GoalHandler struct {
@@ -28,16 +31,56 @@ func (h *GoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !models.GoalType(req.Type).IsValid() {
-		http.Error(w, "Invalid goal type", http.StatusBadRequest)
+	goal, metricType, err := h.newGoal(userID, req)
+	if err != nil {
+		if errors.Is(err, errGoalGroupNotFound) {
+			http.Error(w, "Goal group not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if !models.TrackingFrequency(req.TrackingFrequency).IsValid() {
-		http.Error(w, "Invalid tracking frequency", http.StatusBadRequest)
+	if err := h.db.Transaction(func(tx *gorm.DB) error { return createGoal(tx, goal) }); err != nil {
+		http.Error(w, "Failed to create goal", http.StatusInternalServerError)
 		return
 	}
 
+	goal.MetricType = metricType
+	goal.Localize(unitSystemFor(h.db, userID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(goal)
+}
+
+var errGoalGroupNotFound = errors.New("goal group not found")
+
+// createGoal inserts a goal. gorm fills the weight column's default in for a
+// zero weight, so a goal weighted 0 is set back to it afterwards.
+func createGoal(tx *gorm.DB, goal *models.Goal) error {
+	weight := goal.Weight
+	if err := tx.Create(goal).Error; err != nil {
+		return err
+	}
+	if weight != 0 {
+		return nil
+	}
+	goal.Weight = 0
+	return tx.Model(goal).UpdateColumn("weight", 0).Error
+}
+
+// newGoal builds and validates a goal from a create request, converting the
+// target into base units. It also returns the custom metric type, if any.
+func (h *GoalHandler) newGoal(userID uuid.UUID, req models.CreateGoalRequest) (*models.Goal, *models.MetricType, error) {
+	if !models.GoalType(req.Type).IsValid() {
+		return nil, nil, errors.New("Invalid goal type")
+	}
+
+	if !models.TrackingFrequency(req.TrackingFrequency).IsValid() {
+		return nil, nil, errors.New("Invalid tracking frequency")
+	}
+
 	goal := models.Goal{
 		ID:                uuid.New(),
 		UserID:            userID,
@@ -48,29 +91,108 @@ func (h *GoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
 		TrackingFrequency: req.TrackingFrequency,
 		Target:            req.Target,
 		Unit:              req.Unit,
+		MetricTypeID:      req.MetricTypeID,
+		Aggregation:       req.Aggregation,
+		ScaleMin:          req.ScaleMin,
+		ScaleMax:          req.ScaleMax,
+		ScaleLabels:       req.ScaleLabels,
+		ChecklistRule:     req.ChecklistRule,
+		Direction:         req.Direction,
+		TargetMax:         req.TargetMax,
+		Mode:              req.Mode,
+		StartDate:         req.StartDate,
+		EndDate:           req.EndDate,
+		ChallengeDays:     req.ChallengeDays,
+		ChallengePassDays: req.ChallengePassDays,
 		IsActive:          true,
 		GroupID:           req.GroupID,
 		SortOrder:         req.SortOrder,
+		Weight:            1,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
 
+	if req.Weight != nil {
+		goal.Weight = *req.Weight
+	}
+	if err := goal.ValidateWeight(); err != nil {
+		return nil, nil, err
+	}
+	goal.Palette = req.Palette
+	if err := goal.ValidatePalette(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := goal.ValidateRatingScale(); err != nil {
+		return nil, nil, err
+	}
+
+	metricType, err := h.resolveMetricType(&goal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := goal.ValidateAggregation(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := goal.NormalizeUnit(); err != nil {
+		return nil, nil, err
+	}
+	goal.Target = goal.ToBaseUnit(goal.Target)
+	if goal.TargetMax != nil {
+		targetMax := goal.ToBaseUnit(*goal.TargetMax)
+		goal.TargetMax = &targetMax
+	}
+
+	if goal.Direction == "" {
+		goal.Direction = models.GoalDirectionAtLeast
+	}
+	if err := goal.ValidateDirection(); err != nil {
+		return nil, nil, err
+	}
+	if err := goal.ValidateMode(); err != nil {
+		return nil, nil, err
+	}
+	if err := goal.ValidateChecklist(); err != nil {
+		return nil, nil, err
+	}
+	if goal.IsChecklist() {
+		for i, itemReq := range req.ChecklistItems {
+			if strings.TrimSpace(itemReq.Title) == "" {
+				return nil, nil, errors.New("Checklist item title is required")
+			}
+			goal.ChecklistItems = append(goal.ChecklistItems, models.ChecklistItem{
+				ID:        uuid.New(),
+				GoalID:    goal.ID,
+				Title:     strings.TrimSpace(itemReq.Title),
+				Required:  itemReq.Required,
+				Position:  i,
+				CreatedAt: goal.CreatedAt,
+				UpdatedAt: goal.CreatedAt,
+			})
+		}
+		goal.SyncChecklistTarget()
+	}
+
+	if err := goal.ApplySchedule(req.ScheduleRequest); err != nil {
+		return nil, nil, err
+	}
+
 	if req.GroupID != nil {
 		var group models.GoalGroup
 		if err := h.db.Where("id = ? AND user_id = ?", *req.GroupID, userID).First(&group).Error; err != nil {
-			http.Error(w, "Goal group not found", http.StatusNotFound)
-			return
+			return nil, nil, errGoalGroupNotFound
 		}
 	}
 
-	if err := h.db.Create(&goal).Error; err != nil {
-		http.Error(w, "Failed to create goal", http.StatusInternalServerError)
-		return
+	tags, err := services.ResolveTags(h.db, userID, req.TagIDs)
+	if err != nil {
+		return nil, nil, err
 	}
+	goal.Tags = tags
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(goal)
+	return &goal, metricType, nil
 }
 
 func (h *GoalHandler) GetGoals(w http.ResponseWriter, r *http.Request) {
@@ -91,11 +213,25 @@ func (h *GoalHandler) GetGoals(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := query.Preload("Group").Find(&goals).Error; err != nil {
+	tagIDs, filterByTag, err := tagFilter(h.db, userID, r)
+	if err != nil {
+		http.Error(w, "Failed to fetch tags", http.StatusInternalServerError)
+		return
+	}
+	if filterByTag {
+		query = query.Where("id IN (SELECT goal_id FROM goal_tags WHERE tag_id IN ?)", tagIDs)
+	}
+
+	if err := query.Preload("Group").Preload("MetricType").Preload("Tags").Find(&goals).Error; err != nil {
 		http.Error(w, "Failed to fetch goals", http.StatusInternalServerError)
 		return
 	}
 
+	system := unitSystemFor(h.db, userID)
+	for i := range goals {
+		goals[i].Localize(system)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(goals)
 }
@@ -113,6 +249,9 @@ func (h *GoalHandler) GetGoal(w http.ResponseWriter, r *http.Request) {
 	var goal models.Goal
 	if err := h.db.Where("id = ? AND user_id = ?", parsedGoalID, userID).
 		Preload("Group").
+		Preload("MetricType").
+		Preload("Tags").
+		Preload("ChecklistItems", "archived_at IS NULL", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
 		First(&goal).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			http.Error(w, "Goal not found", http.StatusNotFound)
@@ -122,6 +261,8 @@ func (h *GoalHandler) GetGoal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	goal.Localize(unitSystemFor(h.db, userID))
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(goal)
 }
@@ -175,12 +316,90 @@ func (h *GoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
 		}
 		goal.TrackingFrequency = *req.TrackingFrequency
 	}
-	if req.Target != nil {
-		goal.Target = *req.Target
-	}
 	if req.Unit != nil {
 		goal.Unit = *req.Unit
 	}
+	if req.MetricTypeID != nil {
+		goal.MetricTypeID = req.MetricTypeID
+	}
+	metricType, err := h.resolveMetricType(&goal)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Aggregation != nil {
+		goal.Aggregation = *req.Aggregation
+	}
+	if err := goal.ValidateAggregation(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := goal.NormalizeUnit(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Target != nil {
+		goal.Target = goal.ToBaseUnit(*req.Target)
+	}
+	if req.ScaleMin != nil {
+		goal.ScaleMin = *req.ScaleMin
+	}
+	if req.ScaleMax != nil {
+		goal.ScaleMax = *req.ScaleMax
+	}
+	if req.ScaleLabels != nil {
+		goal.ScaleLabels = req.ScaleLabels
+	}
+	if err := goal.ValidateRatingScale(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.ChecklistRule != nil {
+		goal.ChecklistRule = *req.ChecklistRule
+	}
+	if req.Direction != nil {
+		goal.Direction = *req.Direction
+	}
+	if req.TargetMax != nil {
+		targetMax := goal.ToBaseUnit(*req.TargetMax)
+		goal.TargetMax = &targetMax
+	}
+	if err := goal.ValidateDirection(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Mode != nil {
+		goal.Mode = *req.Mode
+	}
+	if req.StartDate != nil {
+		goal.StartDate = req.StartDate
+	}
+	if req.EndDate != nil {
+		goal.EndDate = req.EndDate
+	} else if goal.Mode == models.GoalModeChallenge {
+		goal.EndDate = nil // Derived again from the start date and duration
+	}
+	if req.ChallengeDays != nil {
+		goal.ChallengeDays = *req.ChallengeDays
+	}
+	if req.ChallengePassDays != nil {
+		goal.ChallengePassDays = *req.ChallengePassDays
+	}
+	if err := goal.ValidateMode(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := goal.ValidateChecklist(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if goal.IsChecklist() {
+		if err := services.LoadChecklistItems(h.db, &goal); err != nil {
+			http.Error(w, "Failed to fetch checklist items", http.StatusInternalServerError)
+			return
+		}
+		goal.SyncChecklistTarget()
+	}
 	if req.IsActive != nil {
 		goal.IsActive = *req.IsActive
 	}
@@ -195,13 +414,62 @@ func (h *GoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
 	if req.SortOrder != nil {
 		goal.SortOrder = *req.SortOrder
 	}
+	if req.Weight != nil {
+		goal.Weight = *req.Weight
+		if err := goal.ValidateWeight(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Palette != nil {
+		goal.Palette = req.Palette
+		if err := goal.ValidatePalette(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := goal.ApplySchedule(req.ScheduleRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var tags []models.Tag
+	if req.TagIDs != nil {
+		if tags, err = services.ResolveTags(h.db, userID, req.TagIDs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	goal.UpdatedAt = time.Now()
+	rescore := req.Target != nil || req.Direction != nil || req.TargetMax != nil ||
+		req.Mode != nil || req.StartDate != nil || req.EndDate != nil ||
+		req.Type != nil || req.ScaleMin != nil || req.ScaleMax != nil || req.ChecklistRule != nil
 
-	if err := h.db.Save(&goal).Error; err != nil {
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&goal).Error; err != nil {
+			return err
+		}
+		if req.TagIDs != nil {
+			if err := tx.Model(&goal).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		}
+		if rescore {
+			return recalculateCompletionRates(tx, &goal)
+		}
+		return nil
+	})
+	if err != nil {
 		http.Error(w, "Failed to update goal", http.StatusInternalServerError)
 		return
 	}
+	if err := h.db.Model(&goal).Association("Tags").Find(&goal.Tags); err != nil {
+		http.Error(w, "Failed to fetch goal tags", http.StatusInternalServerError)
+		return
+	}
+
+	goal.MetricType = metricType
+	goal.Localize(unitSystemFor(h.db, userID))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(goal)
@@ -230,6 +498,45 @@ func (h *GoalHandler) DeleteGoal(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *GoalHandler) GetGoalMilestone(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	goalID := r.PathValue("id")
+
+	parsedGoalID, err := uuid.Parse(goalID)
+	if err != nil {
+		http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+		return
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", parsedGoalID, userID).Preload("MetricType").First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
+		return
+	}
+
+	if !goal.IsCumulative() {
+		http.Error(w, "Goal is not a cumulative goal", http.StatusBadRequest)
+		return
+	}
+
+	days, err := services.DailyProgress(h.db, goal, *goal.StartDate, *goal.EndDate)
+	if err != nil {
+		http.Error(w, "Failed to fetch progress entries", http.StatusInternalServerError)
+		return
+	}
+	daily := make([]models.MilestonePoint, len(days))
+	for i, day := range days {
+		daily[i] = models.MilestonePoint{Date: day.Date, Value: day.Value}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(goal.MilestoneStatus(daily, time.Now()))
+}
+
 func (h *GoalHandler) CreateGoalGroup(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("user_id").(uuid.UUID)
 
@@ -266,12 +573,58 @@ func (h *GoalHandler) GetGoalGroups(w http.ResponseWriter, r *http.Request) {
 	var groups []models.GoalGroup
 	if err := h.db.Where("user_id = ?", userID).
 		Order("sort_order ASC, created_at ASC").
-		Preload("Goals").
+		Preload("Goals.MetricType").
 		Find(&groups).Error; err != nil {
 		http.Error(w, "Failed to fetch goal groups", http.StatusInternalServerError)
 		return
 	}
 
+	system := unitSystemFor(h.db, userID)
+	for i := range groups {
+		for j := range groups[i].Goals {
+			groups[i].Goals[j].Localize(system)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(groups)
-}
\ No newline at end of file
+}
+
+// recalculateCompletionRates re-scores a goal's existing progress after its target
+// or direction changed, so history and the heatmap follow the new definition
+func recalculateCompletionRates(tx *gorm.DB, goal *models.Goal) error {
+	if err := services.LoadChecklistItems(tx, goal); err != nil {
+		return err
+	}
+	var entries []models.Progress
+	if err := tx.Where("goal_id = ?", goal.ID).Preload("Checks").Find(&entries).Error; err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entry.CalculateCompletionRate(goal)
+		if err := tx.Model(&models.Progress{}).Where("id = ?", entry.ID).
+			Update("completion_rate", entry.CompletionRate).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveMetricType loads the metric type a custom goal refers to and copies its
+// unit label onto the goal. Other goal types don't keep a metric type.
+func (h *GoalHandler) resolveMetricType(goal *models.Goal) (*models.MetricType, error) {
+	if goal.Type != models.GoalTypeCustom {
+		goal.MetricTypeID = nil
+		return nil, nil
+	}
+	if goal.MetricTypeID == nil {
+		return nil, errors.New("custom goals must specify metric_type_id")
+	}
+
+	var metricType models.MetricType
+	if err := h.db.Where("id = ? AND user_id = ?", *goal.MetricTypeID, goal.UserID).First(&metricType).Error; err != nil {
+		return nil, errors.New("metric type not found")
+	}
+	goal.Unit = metricType.UnitLabel
+	return &metricType, nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// dryRun returns a session that builds statements without a database,
+// recording the SQL of every one it runs
+func dryRun(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() = %v", err)
+	}
+
+	var statements []string
+	record := func(db *gorm.DB) {
+		statements = append(statements, db.Dialector.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("test:record", record); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("test:record", record); err != nil {
+		t.Fatal(err)
+	}
+	return db, &statements
+}
+
+func TestCreateGoalKeepsZeroWeight(t *testing.T) {
+	for _, weight := range []float64{0, 1, 2.5} {
+		db, statements := dryRun(t)
+		goal := models.Goal{ID: uuid.New(), UserID: uuid.New(), Title: "Reading", Type: models.GoalTypeBoolean, Weight: weight}
+		if err := createGoal(db, &goal); err != nil {
+			t.Fatalf("createGoal() = %v", err)
+		}
+		if goal.Weight != weight {
+			t.Errorf("weight %v became %v", weight, goal.Weight)
+		}
+
+		var updates []string
+		for _, sql := range *statements {
+			if strings.HasPrefix(sql, "UPDATE") {
+				updates = append(updates, sql)
+			}
+		}
+		switch {
+		case weight == 0 && (len(updates) != 1 || !strings.Contains(updates[0], `SET "weight"=0`)):
+			t.Errorf("weight 0 isn't stored: %v", *statements)
+		case weight != 0 && len(updates) != 0:
+			t.Errorf("weight %v is updated after the insert: %v", weight, updates)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/heatmap"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type HeatmapHandler struct {
+	db     *gorm.DB
+	images *heatmap.Cache // Rendered PNGs by data fingerprint
+}
+
+func NewHeatmapHandler(db *gorm.DB) *HeatmapHandler {
+	return &HeatmapHandler{
+		db:     db,
+		images: heatmap.NewCache(256),
+	}
+}
+
+// GetHeatmapSVG renders the heatmap as a contribution calendar, for embedding
+// where the frontend doesn't run
+func (h *HeatmapHandler) GetHeatmapSVG(w http.ResponseWriter, r *http.Request) {
+	filter, ok := heatmapFilterFromRequest(h.db, w, r)
+	if !ok {
+		return
+	}
+	opts, ok := heatmapOptionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	calendar, err := services.HeatmapCalendar(h.db, filter)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	heatmap.RenderSVG(w, calendar, opts)
+}
+
+// GetHeatmapPNG rasterizes the same calendar for places that don't render SVG,
+// such as email digests and chat previews. Images are cached by a fingerprint
+// of the drawn data, which doubles as the ETag.
+func (h *HeatmapHandler) GetHeatmapPNG(w http.ResponseWriter, r *http.Request) {
+	filter, ok := heatmapFilterFromRequest(h.db, w, r)
+	if !ok {
+		return
+	}
+	opts, ok := heatmapOptionsFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	today := models.LocalDate(time.Now(), locationFor(h.db, filter.UserID))
+	fingerprint, err := services.HeatmapFingerprint(h.db, filter, today)
+	if err != nil {
+		http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	writeHeatmapPNG(w, r, h.images, fingerprint, opts, func() (heatmap.Calendar, bool) {
+		calendar, err := services.HeatmapCalendar(h.db, filter)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				http.Error(w, "Goal not found", http.StatusNotFound)
+				return calendar, false
+			}
+			http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+			return calendar, false
+		}
+		return calendar, true
+	})
+}
+
+// writeHeatmapPNG serves a calendar as PNG. Images are cached under the
+// fingerprint of the calendar's data and the options, which is also the ETag,
+// so a repeat request or a matching If-None-Match is answered without building
+// the calendar. build writes its own error response.
+func writeHeatmapPNG(w http.ResponseWriter, r *http.Request, images *heatmap.Cache, fingerprint string, opts heatmap.Options, build func() (heatmap.Calendar, bool)) {
+	key := heatmap.ImageKey(fingerprint, opts)
+	etag := `"` + key + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	image, cached := images.Get(key)
+	if !cached {
+		calendar, ok := build()
+		if !ok {
+			return
+		}
+		var buf bytes.Buffer
+		if err := heatmap.RenderPNG(&buf, calendar, opts); err != nil {
+			http.Error(w, "Failed to render heatmap", http.StatusInternalServerError)
+			return
+		}
+		image = buf.Bytes()
+		images.Put(key, image)
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(image)))
+	w.Write(image)
+}
+
+// heatmapFilterFromRequest reads the range and the goal, group and tag filters
+// shared by the heatmap endpoints. It writes the error response itself.
+func heatmapFilterFromRequest(db *gorm.DB, w http.ResponseWriter, r *http.Request) (models.HeatmapFilter, bool) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	filter := models.HeatmapFilter{UserID: userID}
+
+	var ok bool
+	if filter.StartDate, filter.EndDate, ok = heatmapRangeFromRequest(w, r, locationFor(db, userID)); !ok {
+		return filter, false
+	}
+
+	if goalID := r.URL.Query().Get("goal_id"); goalID != "" {
+		parsedGoalID, err := uuid.Parse(goalID)
+		if err != nil {
+			http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.GoalID = &parsedGoalID
+	}
+	if groupID := r.URL.Query().Get("group_id"); groupID != "" {
+		parsedGroupID, err := uuid.Parse(groupID)
+		if err != nil {
+			http.Error(w, "Invalid group ID", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.GroupID = &parsedGroupID
+	}
+
+	var err error
+	if filter.TagIDs, filter.ByTag, err = tagFilter(db, userID, r); err != nil {
+		http.Error(w, "Failed to fetch tags", http.StatusInternalServerError)
+		return filter, false
+	}
+	return filter, true
+}
+
+// weekStartFromRequest reads week_start as a weekday name, full or abbreviated.
+// It writes the error response itself.
+func weekStartFromRequest(w http.ResponseWriter, r *http.Request, fallback time.Weekday) (time.Weekday, bool) {
+	weekStart := r.URL.Query().Get("week_start")
+	if weekStart == "" {
+		return fallback, true
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(weekStart, day.String()) || strings.EqualFold(weekStart, day.String()[:3]) {
+			return day, true
+		}
+	}
+	http.Error(w, "Invalid week_start, expected a weekday such as 'sunday' or 'monday'", http.StatusBadRequest)
+	return fallback, false
+}
+
+// heatmapRangeFromRequest reads start_date and end_date, defaulting to the
+// year up to today in loc. It writes the error response itself.
+func heatmapRangeFromRequest(w http.ResponseWriter, r *http.Request, loc *time.Location) (time.Time, time.Time, bool) {
+	y, m, d := time.Now().In(loc).Date()
+	end := time.Date(y, m, d, 0, 0, 0, 0, time.UTC) // Default to today
+	start := end.AddDate(-1, 0, 0)                  // Default to 1 year ago
+
+	var err error
+	if value := r.URL.Query().Get("start_date"); value != "" {
+		if start, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
+			return start, end, false
+		}
+	}
+	if value := r.URL.Query().Get("end_date"); value != "" {
+		if end, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
+			return start, end, false
+		}
+	}
+	return start, end, true
+}
+
+// heatmapOptionsFromRequest reads how a heatmap image is drawn: week_start
+// (a weekday name), labels, legend, title, tooltips, cell_size, scale and theme
+func heatmapOptionsFromRequest(w http.ResponseWriter, r *http.Request) (heatmap.Options, bool) {
+	opts := heatmap.DefaultOptions()
+	query := r.URL.Query()
+
+	var ok bool
+	if opts.WeekStart, ok = weekStartFromRequest(w, r, opts.WeekStart); !ok {
+		return opts, false
+	}
+	for name, flag := range map[string]*bool{
+		"labels":   &opts.Labels,
+		"legend":   &opts.Legend,
+		"title":    &opts.Title,
+		"tooltips": &opts.Tooltips,
+	} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				http.Error(w, "Invalid "+name+", expected true or false", http.StatusBadRequest)
+				return opts, false
+			}
+			*flag = parsed
+		}
+	}
+	if cellSize := query.Get("cell_size"); cellSize != "" {
+		parsed, err := strconv.Atoi(cellSize)
+		if err != nil || parsed < heatmap.MinCellSize || parsed > heatmap.MaxCellSize {
+			http.Error(w, "Invalid cell_size", http.StatusBadRequest)
+			return opts, false
+		}
+		opts.Gap = max(1, parsed*3/10)
+		opts.CellSize = parsed
+	}
+	if scale := query.Get("scale"); scale != "" {
+		parsed, err := strconv.Atoi(scale)
+		if err != nil || parsed < 1 || parsed > heatmap.MaxScale {
+			http.Error(w, "Invalid scale, expected 1 to 4", http.StatusBadRequest)
+			return opts, false
+		}
+		opts.Scale = parsed
+	}
+	if theme := query.Get("theme"); theme != "" {
+		opts.Theme = heatmap.Theme(strings.ToLower(theme))
+		if !opts.Theme.IsValid() {
+			http.Error(w, "Invalid theme, expected light or dark", http.StatusBadRequest)
+			return opts, false
+		}
+	}
+	return opts, true
+}
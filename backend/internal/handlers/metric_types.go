@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+type MetricTypeHandler struct {
+	db *gorm.DB
+}
+
+func NewMetricTypeHandler(db *gorm.DB) *MetricTypeHandler {
+	return &MetricTypeHandler{db: db}
+}
+
+func (h *MetricTypeHandler) CreateMetricType(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var req models.CreateMetricTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	metricType := models.MetricType{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Name:           req.Name,
+		UnitLabel:      req.UnitLabel,
+		Precision:      req.Precision,
+		MinValue:       req.MinValue,
+		MaxValue:       req.MaxValue,
+		Aggregate:      req.Aggregation,
+		FormatTemplate: req.FormatTemplate,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := metricType.ValidateDefinition(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var existing models.MetricType
+	if err := h.db.Where("user_id = ? AND name = ?", userID, metricType.Name).First(&existing).Error; err == nil {
+		http.Error(w, "Metric type with this name already exists", http.StatusConflict)
+		return
+	}
+
+	if err := h.db.Create(&metricType).Error; err != nil {
+		http.Error(w, "Failed to create metric type", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(metricType)
+}
+
+func (h *MetricTypeHandler) GetMetricTypes(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var metricTypes []models.MetricType
+	if err := h.db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&metricTypes).Error; err != nil {
+		http.Error(w, "Failed to fetch metric types", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metricTypes)
+}
+
+func (h *MetricTypeHandler) UpdateMetricType(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	metricTypeID := r.PathValue("id")
+
+	parsedMetricTypeID, err := uuid.Parse(metricTypeID)
+	if err != nil {
+		http.Error(w, "Invalid metric type ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateMetricTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var metricType models.MetricType
+	if err := h.db.Where("id = ? AND user_id = ?", parsedMetricTypeID, userID).First(&metricType).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Metric type not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch metric type", http.StatusInternalServerError)
+		return
+	}
+
+	if req.Name != nil {
+		metricType.Name = *req.Name
+	}
+	if req.UnitLabel != nil {
+		metricType.UnitLabel = *req.UnitLabel
+	}
+	if req.Precision != nil {
+		metricType.Precision = *req.Precision
+	}
+	if req.MinValue.Set {
+		metricType.MinValue = req.MinValue.Value
+	}
+	if req.MaxValue.Set {
+		metricType.MaxValue = req.MaxValue.Value
+	}
+	if req.Aggregation != nil {
+		metricType.Aggregate = *req.Aggregation
+	}
+	if req.FormatTemplate != nil {
+		metricType.FormatTemplate = *req.FormatTemplate
+	}
+
+	if err := metricType.ValidateDefinition(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metricType.UpdatedAt = time.Now()
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&metricType).Error; err != nil {
+			return err
+		}
+		// Goals keep a copy of the unit label for display
+		return tx.Model(&models.Goal{}).
+			Where("metric_type_id = ? AND user_id = ?", metricType.ID, userID).
+			Update("unit", metricType.UnitLabel).Error
+	})
+	if err != nil {
+		http.Error(w, "Failed to update metric type", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metricType)
+}
+
+func (h *MetricTypeHandler) DeleteMetricType(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	metricTypeID := r.PathValue("id")
+
+	parsedMetricTypeID, err := uuid.Parse(metricTypeID)
+	if err != nil {
+		http.Error(w, "Invalid metric type ID", http.StatusBadRequest)
+		return
+	}
+
+	var goalCount int64
+	if err := h.db.Model(&models.Goal{}).
+		Where("metric_type_id = ? AND user_id = ?", parsedMetricTypeID, userID).
+		Count(&goalCount).Error; err != nil {
+		http.Error(w, "Failed to delete metric type", http.StatusInternalServerError)
+		return
+	}
+	if goalCount > 0 {
+		http.Error(w, "Metric type is used by existing goals", http.StatusConflict)
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", parsedMetricTypeID, userID).Delete(&models.MetricType{})
+	if result.Error != nil {
+		http.Error(w, "Failed to delete metric type", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Metric type not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
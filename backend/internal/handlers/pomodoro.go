@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/pomodoro"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type PomodoroHandler struct {
+	db        *gorm.DB
+	engine    *pomodoro.Engine
+	pomodoros *services.PomodoroService
+}
+
+func NewPomodoroHandler(db *gorm.DB, engine *pomodoro.Engine, pomodoros *services.PomodoroService) *PomodoroHandler {
+	return &PomodoroHandler{
+		db:        db,
+		engine:    engine,
+		pomodoros: pomodoros,
+	}
+}
+
+func (h *PomodoroHandler) StartPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	parsedGoalID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.StartPomodoroRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", parsedGoalID, userID).First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
+		return
+	}
+	if goal.Type != models.GoalTypeTime {
+		http.Error(w, "Pomodoros can only be credited to time goals", http.StatusBadRequest)
+		return
+	}
+
+	config := pomodoro.DefaultConfig()
+	if req.FocusMinutes > 0 {
+		config.Focus = time.Duration(req.FocusMinutes) * time.Minute
+	}
+	if req.ShortBreakMinutes > 0 {
+		config.ShortBreak = time.Duration(req.ShortBreakMinutes) * time.Minute
+	}
+	if req.LongBreakMinutes > 0 {
+		config.LongBreak = time.Duration(req.LongBreakMinutes) * time.Minute
+	}
+	if req.Cycles > 0 {
+		config.Cycles = req.Cycles
+	}
+
+	snapshot, err := h.engine.Start(userID, goal.ID, config)
+	if err != nil {
+		if errors.Is(err, pomodoro.ErrAlreadyRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+func (h *PomodoroHandler) GetPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	snapshot, running := h.engine.Current(userID)
+	if !running {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// SkipPhase ends the current phase early; a skipped focus interval is recorded
+// as interrupted. Skipping the long break finishes the session.
+func (h *PomodoroHandler) SkipPhase(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	snapshot, running, err := h.engine.Skip(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !running {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+func (h *PomodoroHandler) StopPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	if err := h.engine.Stop(userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StreamPomodoroEvents sends phase changes as server-sent events until the client disconnects
+func (h *PomodoroHandler) StreamPomodoroEvents(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := h.engine.Subscribe(userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Start with the current state so a reconnecting client doesn't wait for the next change
+	if snapshot, running := h.engine.Current(userID); running {
+		data, _ := json.Marshal(snapshot)
+		fmt.Fprintf(w, "event: state\ndata: %s\n\n", data)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *PomodoroHandler) GetPomodoroStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -29) // Default to the last 30 days
+	var err error
+
+	if start := r.URL.Query().Get("start_date"); start != "" {
+		startDate, err = time.Parse("2006-01-02", start)
+		if err != nil {
+			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
+			return
+		}
+	}
+	if end := r.URL.Query().Get("end_date"); end != "" {
+		endDate, err = time.Parse("2006-01-02", end)
+		if err != nil {
+			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var goalID *uuid.UUID
+	if rawGoalID := r.URL.Query().Get("goal_id"); rawGoalID != "" {
+		parsed, err := uuid.Parse(rawGoalID)
+		if err != nil {
+			http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+			return
+		}
+		goalID = &parsed
+	}
+
+	stats, err := h.pomodoros.DailyStats(userID, goalID, startDate, endDate)
+	if err != nil {
+		http.Error(w, "Failed to fetch pomodoro stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -2,14 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/heatmap"
 	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
 )
 
 type ProgressHandler struct {
@@ -30,7 +31,7 @@ func (h *ProgressHandler) CreateProgress(w http.ResponseWriter, r *http.Request)
 	}
 
 	var goal models.Goal
-	if err := h.db.Where("id = ? AND user_id = ?", req.GoalID, userID).First(&goal).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_id = ?", req.GoalID, userID).Preload("MetricType").First(&goal).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			http.Error(w, "Goal not found", http.StatusNotFound)
 			return
@@ -39,14 +40,35 @@ func (h *ProgressHandler) CreateProgress(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var existingProgress models.Progress
-if err := h.db.Where("goal_id = ? AND user_id = ? AND DATE(tracked_date) = DATE(?)", 
-	req.GoalID, userID, req.TrackedDate).First(&existingProgress).Error; err == nil {
-		http.Error(w, "Progress entry already exists for this date", http.StatusConflict)
+	var checks []models.ChecklistCheck
+	if goal.IsChecklist() {
+		if err := services.LoadChecklistItems(h.db, &goal); err != nil {
+			http.Error(w, "Failed to fetch checklist items", http.StatusInternalServerError)
+			return
+		}
+		var err error
+		if checks, err = checklistChecks(&goal, req.CheckedItemIDs, req.TrackedDate); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Value = float64(len(checks))
+	}
+
+	tags, err := services.ResolveTags(h.db, userID, req.TagIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	convertedValue := goal.ConvertInputToBaseUnit(req.Value)
+	convertedValue, err := goal.ConvertInputToBaseUnit(req.Value, req.Unit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := goal.Metric().Validate(convertedValue); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	progress := models.Progress{
 		ID:          uuid.New(),
@@ -55,25 +77,33 @@ if err := h.db.Where("goal_id = ? AND user_id = ? AND DATE(tracked_date) = DATE(
 		Value:       convertedValue,
 		Notes:       req.Notes,
 		TrackedDate: req.TrackedDate,
+		LoggedAt:    loggedAtOrNow(req.LoggedAt),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	for i := range checks {
+		checks[i].ProgressID = progress.ID
+	}
+	progress.Checks = checks
+	progress.Tags = tags
 
-	progress.CalculateCompletionRate(goal.Target)
+	progress.CalculateCompletionRate(&goal)
 
 	if err := h.db.Create(&progress).Error; err != nil {
 		http.Error(w, "Failed to create progress entry", http.StatusInternalServerError)
 		return
 	}
 
-	if err := h.db.Preload("Goal").First(&progress, progress.ID).Error; err != nil {
+	if err := h.db.Preload("Goal.MetricType").Preload("Checks").Preload("Tags").First(&progress, progress.ID).Error; err != nil {
 		http.Error(w, "Failed to fetch created progress", http.StatusInternalServerError)
 		return
 	}
 
+	progress.Localize(&progress.Goal, unitSystemFor(h.db, userID))
 	response := models.ProgressResponse{
 		Progress: progress,
 		Goal:     progress.Goal,
+		Daily:    h.dailyProgressFor(progress),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -91,7 +121,7 @@ func (h *ProgressHandler) CreateTimeProgress(w http.ResponseWriter, r *http.Requ
 	}
 
 	var goal models.Goal
-	if err := h.db.Where("id = ? AND user_id = ?", req.GoalID, userID).First(&goal).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_id = ?", req.GoalID, userID).Preload("MetricType").First(&goal).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			http.Error(w, "Goal not found", http.StatusNotFound)
 			return
@@ -105,13 +135,6 @@ func (h *ProgressHandler) CreateTimeProgress(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var existingProgress models.Progress
-	if err := h.db.Where("goal_id = ? AND user_id = ? AND tracked_date = ?", 
-		req.GoalID, userID, req.TrackedDate.Format("2006-01-02")).First(&existingProgress).Error; err == nil {
-		http.Error(w, "Progress entry already exists for this date", http.StatusConflict)
-		return
-	}
-
 	totalMinutes := req.ConvertToMinutes()
 
 	progress := models.Progress{
@@ -121,25 +144,28 @@ func (h *ProgressHandler) CreateTimeProgress(w http.ResponseWriter, r *http.Requ
 		Value:       totalMinutes,
 		Notes:       req.Notes,
 		TrackedDate: req.TrackedDate,
+		LoggedAt:    loggedAtOrNow(req.LoggedAt),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	progress.CalculateCompletionRate(goal.Target)
+	progress.CalculateCompletionRate(&goal)
 
 	if err := h.db.Create(&progress).Error; err != nil {
 		http.Error(w, "Failed to create progress entry", http.StatusInternalServerError)
 		return
 	}
 
-	if err := h.db.Preload("Goal").First(&progress, progress.ID).Error; err != nil {
+	if err := h.db.Preload("Goal.MetricType").First(&progress, progress.ID).Error; err != nil {
 		http.Error(w, "Failed to fetch created progress", http.StatusInternalServerError)
 		return
 	}
 
+	progress.Localize(&progress.Goal, unitSystemFor(h.db, userID))
 	response := models.ProgressResponse{
 		Progress: progress,
 		Goal:     progress.Goal,
+		Daily:    h.dailyProgressFor(progress),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -179,11 +205,21 @@ func (h *ProgressHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	tagIDs, filterByTag, err := tagFilter(h.db, userID, r)
+	if err != nil {
+		http.Error(w, "Failed to fetch tags", http.StatusInternalServerError)
+		return
+	}
+	if filterByTag {
+		query = query.Scopes(services.TaggedProgress(tagIDs))
+	}
+
 	var progress []models.Progress
 	offset := (page - 1) * limit
 
-	if err := query.Preload("Goal").
-		Order("tracked_date DESC, created_at DESC").
+	if err := query.Preload("Goal.MetricType").
+		Preload("Tags").
+		Order("tracked_date DESC, logged_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&progress).Error; err != nil {
@@ -191,6 +227,11 @@ func (h *ProgressHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	system := unitSystemFor(h.db, userID)
+	for i := range progress {
+		progress[i].Localize(&progress[i].Goal, system)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(progress)
 }
@@ -207,7 +248,8 @@ func (h *ProgressHandler) GetProgressByID(w http.ResponseWriter, r *http.Request
 
 	var progress models.Progress
 	if err := h.db.Where("id = ? AND user_id = ?", parsedProgressID, userID).
-		Preload("Goal").
+		Preload("Goal.MetricType").
+		Preload("Tags").
 		First(&progress).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			http.Error(w, "Progress entry not found", http.StatusNotFound)
@@ -217,6 +259,7 @@ func (h *ProgressHandler) GetProgressByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	progress.Localize(&progress.Goal, unitSystemFor(h.db, userID))
 	response := models.ProgressResponse{
 		Progress: progress,
 		Goal:     progress.Goal,
@@ -244,7 +287,9 @@ func (h *ProgressHandler) UpdateProgress(w http.ResponseWriter, r *http.Request)
 
 	var progress models.Progress
 	if err := h.db.Where("id = ? AND user_id = ?", parsedProgressID, userID).
-		Preload("Goal").
+		Preload("Goal.MetricType").
+		Preload("Checks").
+		Preload("Tags").
 		First(&progress).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			http.Error(w, "Progress entry not found", http.StatusNotFound)
@@ -254,34 +299,87 @@ func (h *ProgressHandler) UpdateProgress(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if progress.Goal.IsChecklist() {
+		if err := services.LoadChecklistItems(h.db, &progress.Goal); err != nil {
+			http.Error(w, "Failed to fetch checklist items", http.StatusInternalServerError)
+			return
+		}
+		// Check-offs decide the value of checklist entries
+		req.Value = nil
+	}
+
 	if req.Value != nil {
-		convertedValue := progress.Goal.ConvertInputToBaseUnit(*req.Value)
+		convertedValue, err := progress.Goal.ConvertInputToBaseUnit(*req.Value, req.Unit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := progress.Goal.Metric().Validate(convertedValue); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		progress.Value = convertedValue
-		progress.CalculateCompletionRate(progress.Goal.Target)
+		progress.CalculateCompletionRate(&progress.Goal)
 	}
 	if req.Notes != nil {
 		progress.Notes = *req.Notes
 	}
 	if req.TrackedDate != nil {
-		var existingProgress models.Progress
-		if err := h.db.Where("goal_id = ? AND user_id = ? AND tracked_date = ? AND id != ?", 
-			progress.GoalID, userID, req.TrackedDate.Format("2006-01-02"), progressID).First(&existingProgress).Error; err == nil {
-			http.Error(w, "Progress entry already exists for this date", http.StatusConflict)
+		progress.TrackedDate = *req.TrackedDate
+	}
+	if req.LoggedAt != nil {
+		progress.LoggedAt = *req.LoggedAt
+	}
+	replaceChecks := progress.Goal.IsChecklist() && req.CheckedItemIDs != nil
+	if replaceChecks {
+		checks, err := checklistChecks(&progress.Goal, req.CheckedItemIDs, progress.TrackedDate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		progress.TrackedDate = *req.TrackedDate
+		for i := range checks {
+			checks[i].ProgressID = progress.ID
+		}
+		progress.Checks = checks
+		progress.Value = float64(len(checks))
+	}
+	if progress.Goal.IsChecklist() {
+		progress.CalculateCompletionRate(&progress.Goal)
+	}
+	if req.TagIDs != nil {
+		tags, err := services.ResolveTags(h.db, userID, req.TagIDs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		progress.Tags = tags
 	}
 
 	progress.UpdatedAt = time.Now()
 
-	if err := h.db.Save(&progress).Error; err != nil {
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if replaceChecks {
+			if err := tx.Where("progress_id = ?", progress.ID).Delete(&models.ChecklistCheck{}).Error; err != nil {
+				return err
+			}
+		}
+		if req.TagIDs != nil {
+			if err := tx.Model(&progress).Association("Tags").Replace(progress.Tags); err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Tags").Save(&progress).Error
+	})
+	if err != nil {
 		http.Error(w, "Failed to update progress entry", http.StatusInternalServerError)
 		return
 	}
 
+	progress.Localize(&progress.Goal, unitSystemFor(h.db, userID))
 	response := models.ProgressResponse{
 		Progress: progress,
 		Goal:     progress.Goal,
+		Daily:    h.dailyProgressFor(progress),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -311,81 +409,102 @@ func (h *ProgressHandler) DeleteProgress(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetHeatmapData returns a cell per goal per logged day, narrowed by goal_id,
+// group_id and tag. mode=dense lists every date instead, and series=score the
+// weighted score.
 func (h *ProgressHandler) GetHeatmapData(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(uuid.UUID)
+	filter, ok := heatmapFilterFromRequest(h.db, w, r)
+	if !ok {
+		return
+	}
 
-	var startDate, endDate time.Time
-	var err error
+	switch series := r.URL.Query().Get("series"); series {
+	case "score":
+		h.getScoreSeries(w, r, filter)
+		return
+	case "", "goals":
+	default:
+		http.Error(w, "Invalid series, expected 'goals' or 'score'", http.StatusBadRequest)
+		return
+	}
 
-	if start := r.URL.Query().Get("start_date"); start != "" {
-		startDate, err = time.Parse("2006-01-02", start)
-		if err != nil {
-			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
-			return
-		}
-	} else {
-		startDate = time.Now().AddDate(-1, 0, 0) // Default to 1 year ago
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "dense":
+		h.getDenseHeatmap(w, r, filter)
+		return
+	case "", "sparse":
+	default:
+		http.Error(w, "Invalid mode, expected 'sparse' or 'dense'", http.StatusBadRequest)
+		return
 	}
 
-	if end := r.URL.Query().Get("end_date"); end != "" {
-		endDate, err = time.Parse("2006-01-02", end)
-		if err != nil {
-			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
-			return
-		}
-	} else {
-		endDate = time.Now() // Default to today
-	}
-
-	var heatmapData []models.HeatmapData
-	query := `
-		SELECT 
-			p.tracked_date as date,
-			p.completion_rate,
-			p.value,
-			g.title as goal_title,
-			g.type as goal_type,
-			g.color_code,
-			g.unit,
-			p.notes
-		FROM progress p
-		JOIN goals g ON p.goal_id = g.id
-		WHERE p.user_id = ? AND p.tracked_date BETWEEN ? AND ?
-		ORDER BY p.tracked_date ASC
-	`
-
-	if err := h.db.Raw(query, userID, startDate, endDate).Scan(&heatmapData).Error; err != nil {
+	heatmapData, err := services.HeatmapCells(h.db, filter)
+	if err != nil {
 		http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
 		return
 	}
 
-	// Format values without additional database queries
-	for i := range heatmapData {
-		heatmapData[i].FormattedValue = formatValueByType(heatmapData[i].GoalType, heatmapData[i].Value, heatmapData[i].Unit)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(heatmapData)
+}
+
+// getDenseHeatmap writes every date in the range, in the user's time zone,
+// with the day's goal cells, an aggregate and its calendar position. Weeks
+// start on week_start, Sunday by default.
+func (h *ProgressHandler) getDenseHeatmap(w http.ResponseWriter, r *http.Request, filter models.HeatmapFilter) {
+	weekStart, ok := weekStartFromRequest(w, r, time.Sunday)
+	if !ok {
+		return
+	}
+	if filter.StartDate.Before(filter.EndDate.AddDate(0, 0, -(heatmap.MaxDays - 1))) {
+		http.Error(w, "Date range is too long for dense mode", http.StatusBadRequest)
+		return
+	}
+
+	dense, err := services.DenseHeatmap(h.db, filter, weekStart, locationFor(h.db, filter.UserID))
+	if err != nil {
+		http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(heatmapData)
+	json.NewEncoder(w).Encode(dense)
 }
 
-// formatValueByType formats a value based on goal type without requiring a Goal object
-func formatValueByType(goalType models.GoalType, value float64, unit string) string {
-	switch goalType {
-	case models.GoalTypeTime:
-		hours := int(value) / 60
-		minutes := int(value) % 60
-		if hours > 0 && minutes > 0 {
-			return fmt.Sprintf("%dh %dm", hours, minutes)
-		} else if hours > 0 {
-			return fmt.Sprintf("%dh", hours)
-		}
-		return fmt.Sprintf("%dm", minutes)
-	case models.GoalTypeBoolean:
-		if value > 0 {
-			return "Completed"
-		}
-		return "Not completed"
-	default:
-		return fmt.Sprintf("%.1f %s", value, unit)
+// getScoreSeries writes the user's weighted productivity score per day, or per
+// week or month with the rollup parameter. Filters narrow the goals scored.
+func (h *ProgressHandler) getScoreSeries(w http.ResponseWriter, r *http.Request, filter models.HeatmapFilter) {
+	rollup := models.ScoreRollup(r.URL.Query().Get("rollup"))
+	if rollup == "" {
+		rollup = models.ScoreRollupDay
+	}
+	if !rollup.IsValid() {
+		http.Error(w, "Invalid rollup, expected 'day', 'week' or 'month'", http.StatusBadRequest)
+		return
 	}
-}
\ No newline at end of file
+
+	points, err := services.ScoreSeries(h.db, filter, rollup)
+	if err != nil {
+		http.Error(w, "Failed to compute score", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(points)
+}
+
+// dailyProgressFor derives the entry's day after a change, for the response
+func (h *ProgressHandler) dailyProgressFor(progress models.Progress) *models.DailyProgress {
+	days, err := services.DailyProgress(h.db, progress.Goal, progress.TrackedDate, progress.TrackedDate)
+	if err != nil || len(days) == 0 {
+		return nil
+	}
+	return &days[0]
+}
+
+func loggedAtOrNow(loggedAt *time.Time) time.Time {
+	if loggedAt != nil {
+		return *loggedAt
+	}
+	return time.Now()
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/heatmap"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type ShareHandler struct {
+	db     *gorm.DB
+	images *heatmap.Cache // Rendered PNGs by data fingerprint
+}
+
+func NewShareHandler(db *gorm.DB) *ShareHandler {
+	return &ShareHandler{
+		db:     db,
+		images: heatmap.NewCache(256),
+	}
+}
+
+func (h *ShareHandler) CreateShare(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var req models.CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.GoalID != nil {
+		var goal models.Goal
+		if err := h.db.Select("id").Where("id = ? AND user_id = ?", *req.GoalID, userID).First(&goal).Error; err != nil {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+	}
+	if req.GroupID != nil {
+		var group models.GoalGroup
+		if err := h.db.Select("id").Where("id = ? AND user_id = ?", *req.GroupID, userID).First(&group).Error; err != nil {
+			http.Error(w, "Goal group not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	token, err := services.NewShareToken()
+	if err != nil {
+		http.Error(w, "Failed to create share link", http.StatusInternalServerError)
+		return
+	}
+
+	link := models.ShareLink{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Token:         token,
+		Scope:         req.Scope,
+		GoalID:        req.GoalID,
+		GroupID:       req.GroupID,
+		Title:         strings.TrimSpace(req.Title),
+		HideNotes:     req.HideNotes,
+		HideValues:    req.HideValues,
+		IntensityOnly: req.IntensityOnly,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := h.db.Create(&link).Error; err != nil {
+		http.Error(w, "Failed to create share link", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.ShareLinkResponse{ShareLink: link, URL: link.Path()})
+}
+
+func (h *ShareHandler) GetShares(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var links []models.ShareLink
+	if err := h.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&links).Error; err != nil {
+		http.Error(w, "Failed to fetch share links", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.ShareLinkResponse, 0, len(links))
+	for _, link := range links {
+		response = append(response, models.ShareLinkResponse{ShareLink: link, URL: link.Path()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *ShareHandler) UpdateShare(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	linkID := r.PathValue("id")
+
+	parsedLinkID, err := uuid.Parse(linkID)
+	if err != nil {
+		http.Error(w, "Invalid share link ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var link models.ShareLink
+	if err := h.db.Where("id = ? AND user_id = ?", parsedLinkID, userID).First(&link).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Share link not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch share link", http.StatusInternalServerError)
+		return
+	}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if len(title) > 255 {
+			http.Error(w, "Title must be at most 255 characters", http.StatusBadRequest)
+			return
+		}
+		link.Title = title
+	}
+	if req.HideNotes != nil {
+		link.HideNotes = *req.HideNotes
+	}
+	if req.HideValues != nil {
+		link.HideValues = *req.HideValues
+	}
+	if req.IntensityOnly != nil {
+		link.IntensityOnly = *req.IntensityOnly
+	}
+
+	link.UpdatedAt = time.Now()
+
+	if err := h.db.Save(&link).Error; err != nil {
+		http.Error(w, "Failed to update share link", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ShareLinkResponse{ShareLink: link, URL: link.Path()})
+}
+
+// DeleteShare revokes a link; its token stops working immediately
+func (h *ShareHandler) DeleteShare(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	linkID := r.PathValue("id")
+
+	parsedLinkID, err := uuid.Parse(linkID)
+	if err != nil {
+		http.Error(w, "Invalid share link ID", http.StatusBadRequest)
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", parsedLinkID, userID).Delete(&models.ShareLink{})
+	if result.Error != nil {
+		http.Error(w, "Failed to delete share link", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Share link not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPublicHeatmap serves a share link without authentication. The extension
+// picks the format: none or .json, .svg, or .png, which take the same drawing
+// options as the signed-in image endpoints.
+func (h *ShareHandler) GetPublicHeatmap(w http.ResponseWriter, r *http.Request) {
+	token, format, _ := strings.Cut(r.PathValue("file"), ".")
+	link, ok := h.linkFromToken(w, token)
+	if !ok {
+		return
+	}
+
+	start, end, ok := heatmapRangeFromRequest(w, r, locationFor(h.db, link.UserID))
+	if !ok {
+		return
+	}
+	if start.Before(end.AddDate(0, 0, -(heatmap.MaxDays - 1))) {
+		start = end.AddDate(0, 0, -(heatmap.MaxDays - 1))
+	}
+
+	switch format {
+	case "", "json":
+		public, err := services.PublicHeatmap(h.db, link, start, end)
+		if err != nil {
+			http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*") // Fetched from personal sites
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(public)
+
+	case "svg", "png":
+		opts, ok := heatmapOptionsFromRequest(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if format == "png" {
+			today := models.LocalDate(time.Now(), locationFor(h.db, link.UserID))
+			fingerprint, err := services.ShareFingerprint(h.db, link, start, end, today)
+			if err != nil {
+				http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+				return
+			}
+			writeHeatmapPNG(w, r, h.images, fingerprint, opts, func() (heatmap.Calendar, bool) {
+				calendar, err := services.ShareCalendar(h.db, link, start, end)
+				if err != nil {
+					http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+					return calendar, false
+				}
+				return calendar, true
+			})
+			return
+		}
+		calendar, err := services.ShareCalendar(h.db, link, start, end)
+		if err != nil {
+			http.Error(w, "Failed to fetch heatmap data", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		heatmap.RenderSVG(w, calendar, opts)
+
+	default:
+		http.Error(w, "Unsupported format, expected json, svg or png", http.StatusNotFound)
+	}
+}
+
+// GetPublicBadge serves a shields-style badge with the link's current streak.
+// The label defaults to "streak" and can be changed with label.
+func (h *ShareHandler) GetPublicBadge(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.linkFromToken(w, r.PathValue("token"))
+	if !ok {
+		return
+	}
+
+	label := "streak"
+	if custom := strings.TrimSpace(r.URL.Query().Get("label")); custom != "" {
+		if len(custom) > 40 {
+			http.Error(w, "Label must be at most 40 characters", http.StatusBadRequest)
+			return
+		}
+		label = custom
+	}
+
+	streak, err := services.ShareStreak(h.db, link)
+	if err != nil {
+		http.Error(w, "Failed to calculate streak", http.StatusInternalServerError)
+		return
+	}
+
+	message := fmt.Sprintf("%d days", streak)
+	color := heatmap.BadgeOnColor
+	switch streak {
+	case 0:
+		color = heatmap.BadgeOffColor
+	case 1:
+		message = "1 day"
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	heatmap.RenderBadge(w, label, message, color)
+}
+
+// linkFromToken finds a live share link. Unknown and revoked tokens get the
+// same 404, so tokens can't be probed. It writes the error response itself.
+func (h *ShareHandler) linkFromToken(w http.ResponseWriter, token string) (*models.ShareLink, bool) {
+	if token == "" {
+		http.Error(w, "Share link not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	var link models.ShareLink
+	if err := h.db.Where("token = ?", token).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Share link not found", http.StatusNotFound)
+			return nil, false
+		}
+		http.Error(w, "Failed to fetch share link", http.StatusInternalServerError)
+		return nil, false
+	}
+	return &link, true
+}
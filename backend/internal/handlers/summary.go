@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type SummaryHandler struct {
+	db         *gorm.DB
+	validation *services.ValidationService
+}
+
+func NewSummaryHandler(db *gorm.DB, validation *services.ValidationService) *SummaryHandler {
+	return &SummaryHandler{
+		db:         db,
+		validation: validation,
+	}
+}
+
+// GetGoalSummary returns a goal's completion statistics and streaks over
+// start_date to end_date, the last 30 days by default
+func (h *SummaryHandler) GetGoalSummary(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	goalID := r.PathValue("id")
+
+	parsedGoalID, err := uuid.Parse(goalID)
+	if err != nil {
+		http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+		return
+	}
+
+	startDate, endDate, ok := summaryRangeFromRequest(w, r, locationFor(h.db, userID))
+	if !ok {
+		return
+	}
+
+	summary, err := h.validation.GetProgressSummary(parsedGoalID, userID, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to build summary", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// GetSummary summarises every active goal, or those of group_id, and totals
+// them. include_inactive=true adds inactive goals too.
+func (h *SummaryHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	startDate, endDate, ok := summaryRangeFromRequest(w, r, locationFor(h.db, userID))
+	if !ok {
+		return
+	}
+
+	query := h.db.Select("id").Where("user_id = ?", userID)
+	if includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("include_inactive")); !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if groupID := r.URL.Query().Get("group_id"); groupID != "" {
+		parsedGroupID, err := uuid.Parse(groupID)
+		if err != nil {
+			http.Error(w, "Invalid group ID", http.StatusBadRequest)
+			return
+		}
+		query = query.Where("group_id = ?", parsedGroupID)
+	}
+
+	var goals []models.Goal
+	if err := query.Order("sort_order ASC, created_at ASC").Find(&goals).Error; err != nil {
+		http.Error(w, "Failed to fetch goals", http.StatusInternalServerError)
+		return
+	}
+
+	overall := models.OverallSummary{
+		StartDate: startDate,
+		EndDate:   endDate,
+		GoalCount: len(goals),
+		Goals:     []models.ProgressSummary{},
+	}
+	var totalCompletion float64
+	for _, goal := range goals {
+		summary, err := h.validation.GetProgressSummary(goal.ID, userID, startDate, endDate)
+		if err != nil {
+			http.Error(w, "Failed to build summary", http.StatusInternalServerError)
+			return
+		}
+		summary.StreakHistory = nil // Kept to the single goal summary
+		overall.Goals = append(overall.Goals, *summary)
+		overall.TotalEntries += summary.TotalEntries
+		overall.TrackedDays += summary.TrackedDays
+		totalCompletion += summary.AverageCompletion * float64(summary.TrackedDays)
+		overall.BestCurrentStreak = max(overall.BestCurrentStreak, summary.CurrentStreak)
+		overall.LongestStreak = max(overall.LongestStreak, summary.LongestStreak)
+	}
+	if overall.TrackedDays > 0 {
+		overall.AverageCompletion = totalCompletion / float64(overall.TrackedDays)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overall)
+}
+
+// summaryRangeFromRequest reads start_date and end_date, defaulting to the 30
+// days up to today in loc. It writes the error response itself.
+func summaryRangeFromRequest(w http.ResponseWriter, r *http.Request, loc *time.Location) (time.Time, time.Time, bool) {
+	endDate := models.LocalDate(time.Now(), loc)
+	if end := r.URL.Query().Get("end_date"); end != "" {
+		parsed, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
+			return endDate, endDate, false
+		}
+		endDate = parsed
+	}
+	startDate := endDate.AddDate(0, 0, -29) // Default to the last 30 days
+	if start := r.URL.Query().Get("start_date"); start != "" {
+		parsed, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
+			return startDate, endDate, false
+		}
+		startDate = parsed
+	}
+	if endDate.Before(startDate) {
+		http.Error(w, "end_date must not be before start_date", http.StatusBadRequest)
+		return startDate, endDate, false
+	}
+	return startDate, endDate, true
+}
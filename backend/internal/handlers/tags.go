@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type TagHandler struct {
+	db *gorm.DB
+}
+
+func NewTagHandler(db *gorm.DB) *TagHandler {
+	return &TagHandler{db: db}
+}
+
+func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var req models.CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 50 {
+		http.Error(w, "Tag name must be 1-50 characters", http.StatusBadRequest)
+		return
+	}
+
+	var existing models.Tag
+	if err := h.db.Where("user_id = ? AND name = ?", userID, name).First(&existing).Error; err == nil {
+		http.Error(w, "Tag with this name already exists", http.StatusConflict)
+		return
+	}
+
+	tag := models.Tag{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		ColorCode: req.ColorCode,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := h.db.Create(&tag).Error; err != nil {
+		http.Error(w, "Failed to create tag", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var tags []models.Tag
+	if err := h.db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&tags).Error; err != nil {
+		http.Error(w, "Failed to fetch tags", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	tagID := r.PathValue("id")
+
+	parsedTagID, err := uuid.Parse(tagID)
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var tag models.Tag
+	if err := h.db.Where("id = ? AND user_id = ?", parsedTagID, userID).First(&tag).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Tag not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch tag", http.StatusInternalServerError)
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > 50 {
+			http.Error(w, "Tag name must be 1-50 characters", http.StatusBadRequest)
+			return
+		}
+		var existing models.Tag
+		if err := h.db.Where("user_id = ? AND name = ? AND id <> ?", userID, name, tag.ID).First(&existing).Error; err == nil {
+			http.Error(w, "Tag with this name already exists", http.StatusConflict)
+			return
+		}
+		tag.Name = name
+	}
+	if req.ColorCode != nil {
+		tag.ColorCode = *req.ColorCode
+	}
+
+	tag.UpdatedAt = time.Now()
+
+	if err := h.db.Save(&tag).Error; err != nil {
+		http.Error(w, "Failed to update tag", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	tagID := r.PathValue("id")
+
+	parsedTagID, err := uuid.Parse(tagID)
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	// Join rows go with the tag through their cascading foreign keys
+	result := h.db.Where("id = ? AND user_id = ?", parsedTagID, userID).Delete(&models.Tag{})
+	if result.Error != nil {
+		http.Error(w, "Failed to delete tag", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Tag not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) GetTagSummaries(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	endDate := time.Now()
+	if end := r.URL.Query().Get("end_date"); end != "" {
+		parsed, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			http.Error(w, "Invalid end_date format", http.StatusBadRequest)
+			return
+		}
+		endDate = parsed
+	}
+	startDate := endDate.AddDate(0, 0, -29) // Default to the last 30 days
+	if start := r.URL.Query().Get("start_date"); start != "" {
+		parsed, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			http.Error(w, "Invalid start_date format", http.StatusBadRequest)
+			return
+		}
+		startDate = parsed
+	}
+
+	summaries, err := services.TagSummaries(h.db, userID, startDate, endDate)
+	if err != nil {
+		http.Error(w, "Failed to build tag summaries", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+// tagFilter reads the repeatable "tag" query parameter, given as tag IDs or
+// names. ok is false when the request doesn't filter by tag.
+func tagFilter(db *gorm.DB, userID uuid.UUID, r *http.Request) (tagIDs []uuid.UUID, ok bool, err error) {
+	values := r.URL.Query()["tag"]
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+	tagIDs, err = services.TagFilterIDs(db, userID, values)
+	return tagIDs, true, err
+}
@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+	"github.com/tarikozturk017/streak-map/backend/internal/templates"
+)
+
+type TemplateHandler struct {
+	db    *gorm.DB
+	goals *GoalHandler
+}
+
+func NewTemplateHandler(db *gorm.DB) *TemplateHandler {
+	return &TemplateHandler{
+		db:    db,
+		goals: NewGoalHandler(db),
+	}
+}
+
+// GetTemplates lists the built-in templates and packs along with the user's saved templates
+func (h *TemplateHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	saved := []models.GoalTemplate{}
+	if err := h.db.Where("user_id = ?", userID).Order("name ASC").Find(&saved).Error; err != nil {
+		http.Error(w, "Failed to fetch templates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TemplateCatalog{
+		Builtins: templates.Builtins(),
+		Packs:    templates.Packs(),
+		Saved:    saved,
+	})
+}
+
+// ExportTemplate returns a built-in (by key) or saved (by ID) template as shareable JSON
+func (h *TemplateHandler) ExportTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+	ref := r.PathValue("ref")
+
+	var document models.TemplateDocument
+	if builtin, ok := templates.Lookup(ref); ok {
+		builtin.Key = ""
+		document = models.TemplateDocument{Name: builtin.Title, Description: builtin.Description, Definition: builtin}
+	} else {
+		templateID, err := uuid.Parse(ref)
+		if err != nil {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		var saved models.GoalTemplate
+		if err := h.db.Where("id = ? AND user_id = ?", templateID, userID).First(&saved).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				http.Error(w, "Template not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+			return
+		}
+		document = models.TemplateDocument{Name: saved.Name, Description: saved.Description, Definition: saved.Definition}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(document)
+}
+
+// ImportTemplate saves a shared template JSON document for the user
+func (h *TemplateHandler) ImportTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var req models.TemplateDocument
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := req.Definition.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		req.Name = req.Definition.Title
+	}
+
+	h.saveTemplate(w, userID, req)
+}
+
+// SaveGoalAsTemplate captures one of the user's goals as a reusable template
+func (h *TemplateHandler) SaveGoalAsTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	parsedGoalID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SaveGoalTemplateRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", parsedGoalID, userID).
+		Preload("MetricType").
+		First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
+		return
+	}
+	if err := services.LoadChecklistItems(h.db, &goal); err != nil {
+		http.Error(w, "Failed to fetch checklist items", http.StatusInternalServerError)
+		return
+	}
+
+	document := models.TemplateDocument{
+		Name:        req.Name,
+		Description: req.Description,
+		Definition:  models.TemplateFromGoal(&goal),
+	}
+	if strings.TrimSpace(document.Name) == "" {
+		document.Name = goal.Title
+	}
+
+	h.saveTemplate(w, userID, document)
+}
+
+func (h *TemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	templateID, err := uuid.Parse(r.PathValue("ref"))
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", templateID, userID).Delete(&models.GoalTemplate{})
+	if result.Error != nil {
+		http.Error(w, "Failed to delete template", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateGoalFromTemplate instantiates a single template, or a whole pack into a new goal group
+func (h *TemplateHandler) CreateGoalFromTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	var req models.CreateGoalFromTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sources := 0
+	for _, set := range []bool{req.TemplateKey != "", req.TemplateID != nil, req.PackKey != "", req.Template != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		http.Error(w, "Specify exactly one of template_key, template_id, pack_key or template", http.StatusBadRequest)
+		return
+	}
+
+	var response models.GoalsFromTemplateResponse
+	var definitions []models.TemplateGoal
+	var group *models.GoalGroup
+
+	switch {
+	case req.PackKey != "":
+		pack, ok := templates.LookupPack(req.PackKey)
+		if !ok {
+			http.Error(w, "Template pack not found", http.StatusNotFound)
+			return
+		}
+		definitions = pack.Goals
+		group = &models.GoalGroup{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Name:        pack.Name,
+			Description: pack.Description,
+			ColorCode:   pack.ColorCode,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+	case req.TemplateKey != "":
+		builtin, ok := templates.Lookup(req.TemplateKey)
+		if !ok {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+		definitions = []models.TemplateGoal{builtin}
+	case req.TemplateID != nil:
+		var saved models.GoalTemplate
+		if err := h.db.Where("id = ? AND user_id = ?", *req.TemplateID, userID).First(&saved).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				http.Error(w, "Template not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to fetch template", http.StatusInternalServerError)
+			return
+		}
+		definitions = []models.TemplateGoal{saved.Definition}
+	default:
+		definitions = []models.TemplateGoal{*req.Template}
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		goals := &GoalHandler{db: tx}
+		if group != nil {
+			if err := tx.Create(group).Error; err != nil {
+				return err
+			}
+			response.Group = group
+		}
+
+		for i, definition := range definitions {
+			if err := definition.Validate(); err != nil {
+				return templateError{err}
+			}
+			createReq := definition.CreateRequest()
+			createReq.SortOrder = i
+			if group != nil {
+				createReq.GroupID = &group.ID
+			} else {
+				if req.Title != "" {
+					createReq.Title = req.Title
+				}
+				if req.ColorCode != "" {
+					createReq.ColorCode = req.ColorCode
+				}
+				if req.Target != nil {
+					createReq.Target = *req.Target
+				}
+				createReq.GroupID = req.GroupID
+			}
+			if definition.Metric != nil {
+				metricType, err := templateMetricType(tx, userID, definition.Metric)
+				if err != nil {
+					return err
+				}
+				createReq.MetricTypeID = &metricType.ID
+			}
+
+			goal, metricType, err := goals.newGoal(userID, createReq)
+			if err != nil {
+				return templateError{err}
+			}
+			if err := createGoal(tx, goal); err != nil {
+				return err
+			}
+			goal.MetricType = metricType
+			response.Goals = append(response.Goals, *goal)
+		}
+		return nil
+	})
+	if err != nil {
+		var invalid templateError
+		switch {
+		case errors.Is(err, errGoalGroupNotFound):
+			http.Error(w, "Goal group not found", http.StatusNotFound)
+		case errors.As(err, &invalid):
+			http.Error(w, invalid.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to create goals from template", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	system := unitSystemFor(h.db, userID)
+	for i := range response.Goals {
+		response.Goals[i].Localize(system)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TemplateHandler) saveTemplate(w http.ResponseWriter, userID uuid.UUID, document models.TemplateDocument) {
+	template := models.GoalTemplate{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        document.Name,
+		Description: document.Description,
+		Definition:  document.Definition,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	template.Definition.Key = ""
+
+	if err := h.db.Create(&template).Error; err != nil {
+		http.Error(w, "Failed to save template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// templateMetricType finds the user's metric type matching a template's
+// definition by name, creating it when the user doesn't have one yet
+func templateMetricType(tx *gorm.DB, userID uuid.UUID, definition *models.TemplateMetric) (*models.MetricType, error) {
+	var metricType models.MetricType
+	err := tx.Where("user_id = ? AND name = ?", userID, definition.Name).First(&metricType).Error
+	if err == nil {
+		return &metricType, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	metricType = models.MetricType{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Name:           definition.Name,
+		UnitLabel:      definition.UnitLabel,
+		Precision:      definition.Precision,
+		MinValue:       definition.MinValue,
+		MaxValue:       definition.MaxValue,
+		Aggregate:      definition.Aggregation,
+		FormatTemplate: definition.FormatTemplate,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if err := metricType.ValidateDefinition(); err != nil {
+		return nil, templateError{err}
+	}
+	if err := tx.Create(&metricType).Error; err != nil {
+		return nil, err
+	}
+	return &metricType, nil
+}
+
+// templateError marks a template that can't be turned into a valid goal
+type templateError struct {
+	err error
+}
+
+func (e templateError) Error() string { return e.err.Error() }
+func (e templateError) Unwrap() error { return e.err }
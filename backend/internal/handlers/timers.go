@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/services"
+)
+
+type TimerHandler struct {
+	db     *gorm.DB
+	timers *services.TimerService
+}
+
+func NewTimerHandler(db *gorm.DB, timers *services.TimerService) *TimerHandler {
+	return &TimerHandler{
+		db:     db,
+		timers: timers,
+	}
+}
+
+func (h *TimerHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.goalFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.StartTimerRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	session, err := h.timers.Start(goal, req.Notes, time.Now())
+	if err != nil {
+		writeTimerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(h.status(session, goal, nil))
+}
+
+func (h *TimerHandler) PauseTimer(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.goalFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.timers.Pause(goal.UserID, goal.ID, time.Now())
+	if err != nil {
+		writeTimerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.status(session, goal, nil))
+}
+
+func (h *TimerHandler) ResumeTimer(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.goalFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.timers.Resume(goal.UserID, goal.ID, time.Now())
+	if err != nil {
+		writeTimerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.status(session, goal, nil))
+}
+
+func (h *TimerHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	goal, ok := h.goalFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.StopTimerRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var user models.User
+	if err := h.db.Select("timezone").First(&user, goal.UserID).Error; err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	session, entries, err := h.timers.Stop(goal, req.Notes, user.Location(), time.Now())
+	if err != nil {
+		writeTimerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.status(session, goal, entries))
+}
+
+// GetActiveTimer lets clients pick a running or paused timer back up, e.g. after a refresh
+func (h *TimerHandler) GetActiveTimer(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	session, err := h.timers.Active(userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch timer", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ?", session.GoalID).First(&goal).Error; err != nil {
+		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.status(session, &goal, nil))
+}
+
+func (h *TimerHandler) goalFromPath(w http.ResponseWriter, r *http.Request) (*models.Goal, bool) {
+	userID := r.Context().Value("user_id").(uuid.UUID)
+
+	parsedGoalID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid goal ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	var goal models.Goal
+	if err := h.db.Where("id = ? AND user_id = ?", parsedGoalID, userID).First(&goal).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Goal not found", http.StatusNotFound)
+			return nil, false
+		}
+		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
+		return nil, false
+	}
+	return &goal, true
+}
+
+func (h *TimerHandler) status(session *models.TimerSession, goal *models.Goal, entries []models.Progress) models.TimerStatus {
+	goal.Localize(unitSystemFor(h.db, goal.UserID))
+	return models.TimerStatus{
+		Session:        *session,
+		Goal:           *goal,
+		ElapsedSeconds: int64(session.Elapsed(time.Now()).Seconds()),
+		Progress:       entries,
+	}
+}
+
+func writeTimerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotTimeGoal):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrNoActiveTimer):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrTimerAlreadyActive),
+		errors.Is(err, services.ErrTimerNotRunning),
+		errors.Is(err, services.ErrTimerNotPaused):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Failed to update timer", http.StatusInternalServerError)
+	}
+}
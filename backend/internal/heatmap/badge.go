@@ -0,0 +1,53 @@
+package heatmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+const (
+	BadgeLabelColor = "#555555"
+	BadgeOnColor    = "#44CC11" // A streak is running
+	BadgeOffColor   = "#9F9F9F" // No current streak
+)
+
+// RenderBadge draws a shields.io style badge: a grey label on the left and
+// the message on a colored background on the right
+func RenderBadge(w io.Writer, label, message, color string) error {
+	labelWidth := badgeTextWidth(label) + 10
+	messageWidth := badgeTextWidth(message) + 10
+	width := labelWidth + messageWidth
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`+"\n",
+		width, escape(label), escape(message))
+	fmt.Fprintf(out, "<title>%s: %s</title>\n", escape(label), escape(message))
+	out.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>` + "\n")
+	fmt.Fprintf(out, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`+"\n", width)
+	out.WriteString(`<g clip-path="url(#r)">` + "\n")
+	fmt.Fprintf(out, `<rect width="%d" height="20" fill="%s"/>`+"\n", labelWidth, BadgeLabelColor)
+	fmt.Fprintf(out, `<rect x="%d" width="%d" height="20" fill="%s"/>`+"\n", labelWidth, messageWidth, color)
+	fmt.Fprintf(out, `<rect width="%d" height="20" fill="url(#s)"/>`+"\n", width)
+	out.WriteString("</g>\n")
+	out.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` + "\n")
+	for _, part := range []struct {
+		x    int
+		text string
+	}{{labelWidth / 2, label}, {labelWidth + messageWidth/2, message}} {
+		fmt.Fprintf(out, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text>`+"\n", part.x, escape(part.text))
+		fmt.Fprintf(out, `<text x="%d" y="14">%s</text>`+"\n", part.x, escape(part.text))
+	}
+	out.WriteString("</g>\n</svg>\n")
+	return out.Flush()
+}
+
+// badgeTextWidth estimates the width of 11px Verdana, which averages about
+// seven pixels a character
+func badgeTextWidth(text string) int {
+	n := 0
+	for range text {
+		n++
+	}
+	return n * 7
+}
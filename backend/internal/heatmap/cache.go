@@ -0,0 +1,53 @@
+package heatmap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// ImageKey identifies a rendering of calendar data with the given
+// fingerprint: it changes whenever the data or an option does, so an image
+// cached under it is still current
+func ImageKey(fingerprint string, opts Options) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%+v\n", fingerprint, opts)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// Cache keeps rendered images by hash, dropping the oldest once full
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string][]byte
+	order   []string
+}
+
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:    size,
+		entries: make(map[string][]byte),
+	}
+}
+
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[key]
+	return data, ok
+}
+
+func (c *Cache) Put(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	for len(c.order) >= c.size && len(c.order) > 0 {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = data
+	c.order = append(c.order, key)
+}
@@ -0,0 +1,242 @@
+// Package heatmap lays out contribution calendars, one cell per day in week
+// columns like GitHub's, and renders them as images.
+package heatmap
+
+import (
+	"time"
+)
+
+// Day is one filled cell of a calendar
+type Day struct {
+	Date    time.Time
+	Level   int
+	Color   string // #RRGGBB
+	Title   string // Tooltip text
+	Excused bool   // Frozen or vacation day, drawn outlined
+}
+
+// Calendar holds the days to draw between Start and End. Days without an
+// entry are drawn in EmptyColor.
+type Calendar struct {
+	Title      string
+	Start      time.Time
+	End        time.Time
+	Days       map[string]Day // Keyed by "2006-01-02"
+	EmptyColor string
+	Legend     []string // Level colors, lowest first
+}
+
+type Theme string
+
+const (
+	ThemeLight Theme = "light"
+	ThemeDark  Theme = "dark"
+)
+
+func (t Theme) IsValid() bool {
+	return t == ThemeLight || t == ThemeDark
+}
+
+type Options struct {
+	WeekStart time.Weekday // First row of every column
+	CellSize  int
+	Gap       int
+	Scale     int   // Pixel multiplier for raster output and SVG dimensions
+	Theme     Theme // Dark draws empty days in its own color
+	Labels    bool  // Month names above and weekday names beside the grid
+	Legend    bool  // Level swatches from "Less" to "More" below the grid
+	Title     bool  // The calendar's title above the grid
+	Tooltips  bool  // A <title> per cell, in formats that support it
+}
+
+func DefaultOptions() Options {
+	return Options{
+		WeekStart: time.Sunday,
+		CellSize:  10,
+		Gap:       3,
+		Scale:     1,
+		Theme:     ThemeLight,
+		Labels:    true,
+		Legend:    true,
+	}
+}
+
+const (
+	MinCellSize = 4
+	MaxCellSize = 40
+	MaxScale    = 4
+	MaxDays     = 366 * 5 // Longest range a calendar is drawn for
+)
+
+// Cell is a positioned day, filled or not
+type Cell struct {
+	X, Y   int
+	Date   time.Time
+	Day    Day
+	Filled bool
+}
+
+// Label is positioned text; Y is the baseline
+type Label struct {
+	X, Y int
+	Text string
+}
+
+type Layout struct {
+	Width, Height int
+	CellSize      int
+	Radius        int
+	Colors        ThemeColors
+	TitleLabel    *Label
+	Cells         []Cell
+	MonthLabels   []Label
+	DayLabels     []Label
+	LegendCells   []Cell // Positioned legend swatches, colors in Day.Color
+	LegendLess    Label
+	LegendMore    Label
+}
+
+// ThemeColors are the colors drawn around the cells
+type ThemeColors struct {
+	Background     string // Empty for a transparent SVG background
+	Text           string
+	Empty          string // Overrides the calendar's empty color when set
+	Outline        string // Faint border keeping light cells visible
+	OutlineOpacity float64
+}
+
+func (t Theme) Colors() ThemeColors {
+	if t == ThemeDark {
+		return ThemeColors{Background: "#0D1117", Text: "#8B949E", Empty: "#161B22", Outline: "#FFFFFF", OutlineOpacity: 0.05}
+	}
+	return ThemeColors{Text: "#767676", Outline: "#1B1F23", OutlineOpacity: 0.06}
+}
+
+const (
+	labelLeft   = 28 // Room for weekday names
+	labelTop    = 15 // Room for month names
+	titleSpace  = 16 // Room for the title
+	legendSpace = 20 // Room for the legend below the grid
+	fontSize    = 9
+)
+
+// Layout positions every day from Start to End. Columns are weeks starting on
+// opts.WeekStart; month names sit above the first column of each month, and
+// Monday, Wednesday and Friday are named on the left.
+func (c Calendar) Layout(opts Options) Layout {
+	start := dateOnly(c.Start)
+	end := dateOnly(c.End)
+	if end.Before(start) {
+		end = start
+	}
+	if days := int(end.Sub(start).Hours() / 24); days >= MaxDays {
+		start = end.AddDate(0, 0, -(MaxDays - 1))
+	}
+
+	colors := opts.Theme.Colors()
+	emptyColor := c.EmptyColor
+	if colors.Empty != "" {
+		emptyColor = colors.Empty
+	}
+
+	layout := Layout{CellSize: opts.CellSize, Radius: opts.CellSize / 5, Colors: colors}
+	left, top := 0, 0
+	if opts.Title && c.Title != "" {
+		layout.TitleLabel = &Label{X: 0, Y: fontSize + 2, Text: c.Title}
+		top += titleSpace
+	}
+	if opts.Labels {
+		left = labelLeft
+		top += labelTop
+	}
+	step := opts.CellSize + opts.Gap
+	firstColumn := start.AddDate(0, 0, -rowOf(start, opts.WeekStart))
+
+	lastMonthColumn := -3
+	columns := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		column := int(day.Sub(firstColumn).Hours()/24) / 7
+		row := rowOf(day, opts.WeekStart)
+		cell := Cell{
+			X:    left + column*step,
+			Y:    top + row*step,
+			Date: day,
+		}
+		cell.Day, cell.Filled = c.Days[day.Format("2006-01-02")]
+		if !cell.Filled {
+			cell.Day = Day{Date: day, Color: emptyColor}
+		} else if cell.Day.Level == 0 && colors.Empty != "" {
+			cell.Day.Color = colors.Empty // Level 0 is the palette's blank, white by default
+		}
+		layout.Cells = append(layout.Cells, cell)
+		columns = column + 1
+
+		// Names need three columns of room so neighbours don't overlap
+		if opts.Labels && (day.Day() == 1 || day.Equal(start)) && column-lastMonthColumn >= 3 {
+			layout.MonthLabels = append(layout.MonthLabels, Label{
+				X:    left + column*step,
+				Y:    top - 5,
+				Text: day.Format("Jan"),
+			})
+			lastMonthColumn = column
+		}
+	}
+
+	if opts.Labels {
+		for _, weekday := range []time.Weekday{time.Monday, time.Wednesday, time.Friday} {
+			row := weekdayRow(weekday, opts.WeekStart)
+			layout.DayLabels = append(layout.DayLabels, Label{
+				X:    0,
+				Y:    top + row*step + opts.CellSize - 1,
+				Text: weekday.String()[:3],
+			})
+		}
+	}
+
+	layout.Width = left + columns*step - opts.Gap
+	layout.Height = top + 7*step - opts.Gap
+
+	if opts.Legend && len(c.Legend) > 0 {
+		legendWidth := len(c.Legend)*step - opts.Gap
+		y := layout.Height + legendSpace - opts.CellSize
+		x := layout.Width - legendWidth - 30 // "More" sits right of the swatches
+		if x < left+30 {
+			x = left + 30
+		}
+		layout.LegendLess = Label{X: x - 28, Y: y + opts.CellSize - 1, Text: "Less"}
+		for i, color := range c.Legend {
+			if i == 0 && colors.Empty != "" {
+				color = colors.Empty
+			}
+			layout.LegendCells = append(layout.LegendCells, Cell{
+				X:      x + i*step,
+				Y:      y,
+				Day:    Day{Level: i, Color: color},
+				Filled: true,
+			})
+		}
+		layout.LegendMore = Label{X: x + legendWidth + 4, Y: y + opts.CellSize - 1, Text: "More"}
+		if right := layout.LegendMore.X + 26; right > layout.Width {
+			layout.Width = right
+		}
+		layout.Height += legendSpace
+	}
+	if layout.TitleLabel != nil {
+		layout.Width = max(layout.Width, textWidth(c.Title))
+	}
+	return layout
+}
+
+// rowOf is the day's row in its column, 0 being the week start
+func rowOf(day time.Time, weekStart time.Weekday) int {
+	return weekdayRow(day.Weekday(), weekStart)
+}
+
+func weekdayRow(weekday, weekStart time.Weekday) int {
+	return (int(weekday) - int(weekStart) + 7) % 7
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,187 @@
+package heatmap
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+// RenderPNG rasterizes the calendar with the same layout as RenderSVG, every
+// layout pixel drawn as a Scale x Scale block. Text uses a built-in 5x7 bitmap
+// font, so non-ASCII characters in titles come out as '?'.
+func RenderPNG(w io.Writer, c Calendar, opts Options) error {
+	layout := c.Layout(opts)
+	scale := min(max(1, opts.Scale), MaxScale)
+	canvas := &raster{
+		img:   image.NewNRGBA(image.Rect(0, 0, layout.Width*scale, layout.Height*scale)),
+		scale: scale,
+	}
+
+	colors := layout.Colors
+	background := colors.Background
+	if background == "" {
+		background = "#FFFFFF" // Email clients may put transparent images on dark backgrounds
+	}
+	canvas.fill(parseColor(background))
+
+	text := parseColor(colors.Text)
+	if layout.TitleLabel != nil {
+		// Drawn twice, a pixel apart, to stand in for bold
+		canvas.text(*layout.TitleLabel, text)
+		canvas.text(Label{X: layout.TitleLabel.X + 1, Y: layout.TitleLabel.Y, Text: layout.TitleLabel.Text}, text)
+	}
+	for _, label := range layout.MonthLabels {
+		canvas.text(label, text)
+	}
+	for _, label := range layout.DayLabels {
+		canvas.text(label, text)
+	}
+
+	outline := parseColor(colors.Outline)
+	outline.A = uint8(colors.OutlineOpacity * 255)
+	for _, cell := range layout.Cells {
+		if cell.Day.Excused {
+			canvas.cell(cell, layout, parseColor(cell.Day.Color), text, true)
+		} else {
+			canvas.cell(cell, layout, parseColor(cell.Day.Color), outline, false)
+		}
+	}
+
+	if len(layout.LegendCells) > 0 {
+		canvas.text(layout.LegendLess, text)
+		for _, cell := range layout.LegendCells {
+			canvas.cell(cell, layout, parseColor(cell.Day.Color), outline, false)
+		}
+		canvas.text(layout.LegendMore, text)
+	}
+
+	return png.Encode(w, canvas.img)
+}
+
+type raster struct {
+	img   *image.NRGBA
+	scale int
+}
+
+func (r *raster) fill(c color.NRGBA) {
+	bounds := r.img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r.img.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+// blend draws c over the pixel at its alpha
+func (r *raster) blend(x, y int, c color.NRGBA) {
+	if !(image.Point{x, y}.In(r.img.Bounds())) {
+		return
+	}
+	if c.A == 255 {
+		r.img.SetNRGBA(x, y, c)
+		return
+	}
+	dst := r.img.NRGBAAt(x, y)
+	mix := func(src, dst uint8) uint8 {
+		return uint8((int(src)*int(c.A) + int(dst)*(255-int(c.A))) / 255)
+	}
+	r.img.SetNRGBA(x, y, color.NRGBA{mix(c.R, dst.R), mix(c.G, dst.G), mix(c.B, dst.B), max(dst.A, c.A)})
+}
+
+// cell draws a rounded square with a one unit border: solid and blended, or
+// dashed two on, one off like the SVG's stroke-dasharray
+func (r *raster) cell(cell Cell, layout Layout, fill, border color.NRGBA, dashed bool) {
+	size := layout.CellSize * r.scale
+	radius := layout.Radius * r.scale
+	x0, y0 := cell.X*r.scale, cell.Y*r.scale
+	for dy := 0; dy < size; dy++ {
+		for dx := 0; dx < size; dx++ {
+			if outsideCorner(dx, dy, size, radius) {
+				continue
+			}
+			r.blend(x0+dx, y0+dy, fill)
+
+			edge := dx < r.scale || dy < r.scale || dx >= size-r.scale || dy >= size-r.scale
+			if !edge {
+				continue
+			}
+			if dashed {
+				along := dx
+				if dx < r.scale || dx >= size-r.scale {
+					along = dy
+				}
+				if (along/r.scale)%3 == 2 {
+					continue
+				}
+			}
+			r.blend(x0+dx, y0+dy, border)
+		}
+	}
+}
+
+// outsideCorner reports whether a pixel of a size x size square falls outside
+// its rounded corners
+func outsideCorner(dx, dy, size, radius int) bool {
+	if radius <= 0 {
+		return false
+	}
+	cx, cy := -1, -1
+	if dx < radius {
+		cx = radius
+	} else if dx >= size-radius {
+		cx = size - radius
+	}
+	if dy < radius {
+		cy = radius
+	} else if dy >= size-radius {
+		cy = size - radius
+	}
+	if cx < 0 || cy < 0 {
+		return false
+	}
+	// Measured from pixel centres
+	fx := float64(dx) + 0.5 - float64(cx)
+	fy := float64(dy) + 0.5 - float64(cy)
+	return fx*fx+fy*fy > float64(radius*radius)
+}
+
+// text draws a label with its baseline on the glyphs' bottom row
+func (r *raster) text(label Label, c color.NRGBA) {
+	x := label.X
+	top := label.Y - glyphHeight + 1
+	for _, ch := range label.Text {
+		bitmap := glyph(ch)
+		for column, bits := range bitmap {
+			for row := 0; row < glyphHeight; row++ {
+				if bits&(1<<row) == 0 {
+					continue
+				}
+				r.block(x+column, top+row, c)
+			}
+		}
+		x += glyphAdvance
+	}
+}
+
+// block draws one layout pixel
+func (r *raster) block(x, y int, c color.NRGBA) {
+	for dy := 0; dy < r.scale; dy++ {
+		for dx := 0; dx < r.scale; dx++ {
+			r.blend(x*r.scale+dx, y*r.scale+dy, c)
+		}
+	}
+}
+
+// parseColor reads #RRGGBB, falling back to black
+func parseColor(hex string) color.NRGBA {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.NRGBA{A: 255}
+	}
+	value, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.NRGBA{A: 255}
+	}
+	return color.NRGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}
+}
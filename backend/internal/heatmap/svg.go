@@ -0,0 +1,75 @@
+package heatmap
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderSVG draws the calendar as a standalone SVG document
+func RenderSVG(w io.Writer, c Calendar, opts Options) error {
+	layout := c.Layout(opts)
+	colors := layout.Colors
+	scale := max(1, opts.Scale)
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif" font-size="%d">`,
+		layout.Width*scale, layout.Height*scale, layout.Width, layout.Height, fontSize)
+	out.WriteString("\n")
+	if c.Title != "" {
+		fmt.Fprintf(out, "<title>%s</title>\n", escape(c.Title))
+	}
+	if colors.Background != "" {
+		fmt.Fprintf(out, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", colors.Background)
+	}
+
+	if layout.TitleLabel != nil {
+		fmt.Fprintf(out, `<text x="%d" y="%d" fill="%s" font-weight="600">%s</text>`+"\n",
+			layout.TitleLabel.X, layout.TitleLabel.Y, colors.Text, escape(layout.TitleLabel.Text))
+	}
+	for _, label := range layout.MonthLabels {
+		writeLabel(out, label, colors)
+	}
+	for _, label := range layout.DayLabels {
+		writeLabel(out, label, colors)
+	}
+
+	for _, cell := range layout.Cells {
+		fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="%s"`,
+			cell.X, cell.Y, layout.CellSize, layout.CellSize, layout.Radius, cell.Day.Color)
+		if cell.Day.Excused {
+			out.WriteString(` stroke="` + colors.Text + `" stroke-width="1" stroke-dasharray="2 1"`)
+		} else {
+			fmt.Fprintf(out, ` stroke="%s" stroke-opacity="%g"`, colors.Outline, colors.OutlineOpacity)
+		}
+		if opts.Tooltips && cell.Day.Title != "" {
+			fmt.Fprintf(out, "><title>%s</title></rect>\n", escape(cell.Day.Title))
+		} else {
+			out.WriteString("/>\n")
+		}
+	}
+
+	if len(layout.LegendCells) > 0 {
+		writeLabel(out, layout.LegendLess, colors)
+		for _, cell := range layout.LegendCells {
+			fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="%s" stroke="%s" stroke-opacity="%g"/>`+"\n",
+				cell.X, cell.Y, layout.CellSize, layout.CellSize, layout.Radius, cell.Day.Color, colors.Outline, colors.OutlineOpacity)
+		}
+		writeLabel(out, layout.LegendMore, colors)
+	}
+
+	out.WriteString("</svg>\n")
+	return out.Flush()
+}
+
+func writeLabel(out *bufio.Writer, label Label, colors ThemeColors) {
+	fmt.Fprintf(out, `<text x="%d" y="%d" fill="%s">%s</text>`+"\n", label.X, label.Y, colors.Text, escape(label.Text))
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChallengeState string
+
+const (
+	ChallengeStateUpcoming ChallengeState = "upcoming"
+	ChallengeStateActive   ChallengeState = "active"
+	ChallengeStatePassed   ChallengeState = "passed"
+	ChallengeStateFailed   ChallengeState = "failed"
+)
+
+// ChallengeResult is the final outcome of a challenge, recorded once its last day is over
+type ChallengeResult struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GoalID         uuid.UUID `json:"goal_id" gorm:"type:uuid;not null;uniqueIndex"` // One outcome per challenge
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Passed         bool      `json:"passed" gorm:"not null"`
+	SuccessfulDays int       `json:"successful_days" gorm:"not null"`
+	RequiredDays   int       `json:"required_days" gorm:"not null"`
+	TotalDays      int       `json:"total_days" gorm:"not null"`
+	StartDate      time.Time `json:"start_date" gorm:"type:date;not null"`
+	EndDate        time.Time `json:"end_date" gorm:"type:date;not null"`
+	CompletedAt    time.Time `json:"completed_at"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// Relationships
+	Goal Goal `json:"-" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type ChallengeStatus struct {
+	GoalID         uuid.UUID        `json:"goal_id"`
+	GoalTitle      string           `json:"goal_title"`
+	State          ChallengeState   `json:"state"`
+	StartDate      time.Time        `json:"start_date"`
+	EndDate        time.Time        `json:"end_date"`
+	CurrentDay     int              `json:"current_day"` // Day N of TotalDays, 0 before the start
+	TotalDays      int              `json:"total_days"`
+	DaysRemaining  int              `json:"days_remaining"` // Scheduled days still to come, today included when it hasn't succeeded yet
+	SuccessfulDays int              `json:"successful_days"`
+	MissedDays     int              `json:"missed_days"`
+	RequiredDays   int              `json:"required_days"`
+	StillWinnable  bool             `json:"still_winnable"`
+	Result         *ChallengeResult `json:"result,omitempty"`
+}
+
+func (g *Goal) IsChallenge() bool {
+	return g.Mode == GoalModeChallenge
+}
+
+// RequiredChallengeDays is how many successful days the challenge needs to pass.
+// Zero means every scheduled day of the challenge.
+func (g *Goal) RequiredChallengeDays() int {
+	if g.ChallengePassDays > 0 {
+		return g.ChallengePassDays
+	}
+	if g.StartDate == nil {
+		return g.ChallengeDays
+	}
+	start := dateOnly(*g.StartDate)
+	return g.scheduledDaysBetween(start, start.AddDate(0, 0, g.ChallengeDays-1))
+}
+
+// scheduledDaysBetween counts the goal's scheduled days from one day to another, inclusive
+func (g *Goal) scheduledDaysBetween(from, to time.Time) int {
+	var days int
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if g.IsScheduledOn(day) {
+			days++
+		}
+	}
+	return days
+}
+
+// validateChallengeSchedule checks that the challenge can be passed on the days
+// its schedule leaves. It runs once the schedule is applied.
+func (g *Goal) validateChallengeSchedule() error {
+	start := dateOnly(*g.StartDate)
+	scheduled := g.scheduledDaysBetween(start, start.AddDate(0, 0, g.ChallengeDays-1))
+	if scheduled == 0 {
+		return fmt.Errorf("the schedule leaves no days in the challenge")
+	}
+	if g.ChallengePassDays > scheduled {
+		return fmt.Errorf("challenge pass days must not exceed the %d scheduled days", scheduled)
+	}
+	return nil
+}
+
+// validateChallenge checks the challenge rule and derives EndDate from the
+// duration. A given EndDate must agree with it.
+func (g *Goal) validateChallenge() error {
+	if g.StartDate == nil {
+		start := dateOnly(time.Now())
+		g.StartDate = &start
+	}
+	if g.ChallengeDays < 1 || g.ChallengeDays > 366 {
+		return fmt.Errorf("challenge duration must be between 1 and 366 days")
+	}
+	if g.ChallengePassDays < 0 || g.ChallengePassDays > g.ChallengeDays {
+		return fmt.Errorf("challenge pass days must be between 0 and the duration")
+	}
+	end := dateOnly(*g.StartDate).AddDate(0, 0, g.ChallengeDays-1)
+	if g.EndDate != nil && !dateOnly(*g.EndDate).Equal(end) {
+		return fmt.Errorf("end_date of a %d-day challenge must be %s, or left out", g.ChallengeDays, end.Format("2006-01-02"))
+	}
+	g.EndDate = &end
+	return nil
+}
+
+// ChallengeStatus evaluates the challenge as of the given day. successDays holds
+// the dates ("2006-01-02") on which the goal was met.
+func (g *Goal) ChallengeStatus(successDays map[string]bool, asOf time.Time) ChallengeStatus {
+	start := dateOnly(*g.StartDate)
+	end := start.AddDate(0, 0, g.ChallengeDays-1)
+	today := dateOnly(asOf)
+
+	status := ChallengeStatus{
+		GoalID:       g.ID,
+		GoalTitle:    g.Title,
+		StartDate:    start,
+		EndDate:      end,
+		TotalDays:    g.ChallengeDays,
+		RequiredDays: g.RequiredChallengeDays(),
+	}
+
+	for day := start; !day.After(end) && !day.After(today); day = day.AddDate(0, 0, 1) {
+		if !g.IsScheduledOn(day) {
+			continue // Neither counts for nor against the challenge
+		}
+		if successDays[day.Format("2006-01-02")] {
+			status.SuccessfulDays++
+		} else if day.Before(today) {
+			status.MissedDays++
+		}
+	}
+
+	switch {
+	case today.Before(start):
+		status.State = ChallengeStateUpcoming
+		status.DaysRemaining = g.scheduledDaysBetween(start, end)
+	case today.After(end):
+		status.CurrentDay = g.ChallengeDays
+		if status.SuccessfulDays >= status.RequiredDays {
+			status.State = ChallengeStatePassed
+		} else {
+			status.State = ChallengeStateFailed
+		}
+	default:
+		status.State = ChallengeStateActive
+		status.CurrentDay = daysBetween(start, today) + 1
+		status.DaysRemaining = g.scheduledDaysBetween(today.AddDate(0, 0, 1), end)
+		if g.IsScheduledOn(today) && !successDays[today.Format("2006-01-02")] {
+			status.DaysRemaining++
+		}
+	}
+
+	status.StillWinnable = status.SuccessfulDays+status.DaysRemaining >= status.RequiredDays
+	return status
+}
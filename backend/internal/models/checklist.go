@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
+)
+
+type ChecklistRule string
+
+const (
+	ChecklistRuleFraction ChecklistRule = "fraction" // Completion is the share of items done (the default)
+	ChecklistRuleRequired ChecklistRule = "required" // Completion is the share of required items done
+)
+
+func (r ChecklistRule) IsValid() bool {
+	switch r {
+	case ChecklistRuleFraction, ChecklistRuleRequired:
+		return true
+	}
+	return false
+}
+
+// ChecklistItem is one step of a checklist goal (e.g., "stretch" in "morning routine").
+// Archived items stop counting from the day they were archived; earlier check-offs stay.
+type ChecklistItem struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GoalID     uuid.UUID  `json:"goal_id" gorm:"type:uuid;not null;index"`
+	Title      string     `json:"title" gorm:"not null;size:255"`
+	Required   bool       `json:"required" gorm:"not null;default:false"`
+	Position   int        `json:"position" gorm:"not null;default:0"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Goal Goal `json:"-" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+}
+
+// ChecklistCheck records that an item was done as part of a progress entry
+type ChecklistCheck struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProgressID uuid.UUID `json:"progress_id" gorm:"type:uuid;not null;uniqueIndex:idx_checklist_checks_progress_item"`
+	ItemID     uuid.UUID `json:"item_id" gorm:"type:uuid;not null;uniqueIndex:idx_checklist_checks_progress_item;index"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Progress Progress      `json:"-" gorm:"foreignKey:ProgressID;constraint:OnDelete:CASCADE"`
+	Item     ChecklistItem `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+}
+
+type CreateChecklistItemRequest struct {
+	Title    string `json:"title" validate:"required,max=255"`
+	Required bool   `json:"required"`
+}
+
+type UpdateChecklistItemRequest struct {
+	Title    *string `json:"title,omitempty" validate:"omitempty,max=255"`
+	Required *bool   `json:"required,omitempty"`
+	Archived *bool   `json:"archived,omitempty"`
+}
+
+type ReorderChecklistRequest struct {
+	ItemIDs []uuid.UUID `json:"item_ids" validate:"required"` // Active items, in their new order
+}
+
+func (g *Goal) IsChecklist() bool {
+	return g.Type == GoalTypeChecklist
+}
+
+// ItemCountsOn reports whether an item was on the list on the given day. Items
+// count from the day they were added, except the goal's initial items, which
+// also cover backfilled days. Archived items stop counting from their archive day.
+func (g *Goal) ItemCountsOn(item *ChecklistItem, date time.Time) bool {
+	day := dateOnly(date)
+	if item.ArchivedAt != nil && !dateOnly(*item.ArchivedAt).After(day) {
+		return false
+	}
+	added := dateOnly(item.CreatedAt)
+	return !added.After(day) || !added.After(dateOnly(g.CreatedAt))
+}
+
+// ChecklistCompletion derives a day's completion from the items done that day.
+// Items done that day always count, even if they were added or archived around it.
+func (g *Goal) ChecklistCompletion(date time.Time, done map[uuid.UUID]bool) float64 {
+	var total, completed, required, requiredDone int
+	for i := range g.ChecklistItems {
+		item := &g.ChecklistItems[i]
+		if !g.ItemCountsOn(item, date) && !done[item.ID] {
+			continue
+		}
+		total++
+		if done[item.ID] {
+			completed++
+		}
+		if item.Required {
+			required++
+			if done[item.ID] {
+				requiredDone++
+			}
+		}
+	}
+
+	if g.ChecklistRule == ChecklistRuleRequired && required > 0 {
+		return float64(requiredDone) / float64(required) * 100
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(completed) / float64(total) * 100
+}
+
+// SyncChecklistTarget keeps Target at the number of items a day is measured
+// against, so lists and displays that only know the target stay meaningful
+func (g *Goal) SyncChecklistTarget() {
+	var active, required int
+	now := time.Now()
+	for i := range g.ChecklistItems {
+		if g.ItemCountsOn(&g.ChecklistItems[i], now) {
+			active++
+			if g.ChecklistItems[i].Required {
+				required++
+			}
+		}
+	}
+	g.Target = float64(active)
+	if g.ChecklistRule == ChecklistRuleRequired && required > 0 {
+		g.Target = float64(required)
+	}
+}
+
+// ValidateChecklist fills in the default rule. Checklists only count up.
+func (g *Goal) ValidateChecklist() error {
+	if !g.IsChecklist() {
+		return nil
+	}
+	if g.ChecklistRule == "" {
+		g.ChecklistRule = ChecklistRuleFraction
+	}
+	if !g.ChecklistRule.IsValid() {
+		return fmt.Errorf("invalid checklist rule %q", g.ChecklistRule)
+	}
+	if g.Direction != GoalDirectionAtLeast {
+		return fmt.Errorf("checklist goals only support the at_least direction")
+	}
+	if g.Unit == "" {
+		g.Unit = "items"
+	}
+	return nil
+}
+
+// CheckedItems is the set of items done in a single entry
+func (p *Progress) CheckedItems() map[uuid.UUID]bool {
+	done := make(map[uuid.UUID]bool, len(p.Checks))
+	for _, check := range p.Checks {
+		done[check.ItemID] = true
+	}
+	return done
+}
+
+type checklistMetric struct{}
+
+func (checklistMetric) Validate(value float64) error {
+	if value < 0 {
+		return fmt.Errorf("checklist value must be non-negative")
+	}
+	return nil
+}
+
+func (checklistMetric) Format(value float64, _ units.System) string {
+	if value == 1 {
+		return "1 item"
+	}
+	return fmt.Sprintf("%.0f items", value)
+}
+
+// Checklist days are combined by item, see AggregateDaily; max is the fallback
+func (checklistMetric) Aggregation() Aggregation { return AggregationMax }
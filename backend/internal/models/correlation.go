@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarikozturk017/streak-map/backend/internal/stats"
+)
+
+const (
+	MaxCorrelationLag         = 30   // Days the later series can trail the earlier one
+	MinCorrelationOverlap     = 3    // Fewest shared days a correlation can be computed from
+	DefaultCorrelationOverlap = 14   // Shared days required when not asked otherwise
+	CorrelationSignificance   = 0.05 // p-value below which a correlation is flagged significant
+)
+
+// CorrelationOptions shape a correlation analysis. With a lag, each goal's day
+// is paired with the other goal's day that many days later, so ordered pairs
+// differ: sleep against the next day's study is not study against the next
+// day's sleep.
+type CorrelationOptions struct {
+	Lag        int `json:"lag"`
+	MinOverlap int `json:"min_overlap"`
+}
+
+// CorrelationResponse lists the goal pairs with enough shared days, the
+// strongest correlations first
+type CorrelationResponse struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	CorrelationOptions
+	Pairs []GoalCorrelation `json:"pairs"`
+}
+
+// GoalCorrelation relates two goals' daily completion series
+type GoalCorrelation struct {
+	GoalID          uuid.UUID `json:"goal_id"`
+	GoalTitle       string    `json:"goal_title"`
+	LaggedGoalID    uuid.UUID `json:"lagged_goal_id"` // Compared lag days after the first goal
+	LaggedGoalTitle string    `json:"lagged_goal_title"`
+	Overlap         int       `json:"overlap"`     // Days both series count
+	Coefficient     float64   `json:"coefficient"` // Pearson's r, -1 to 1
+	PValue          float64   `json:"p_value"`     // Two-sided, against no correlation
+	ConfidenceLow   float64   `json:"confidence_low"`
+	ConfidenceHigh  float64   `json:"confidence_high"` // 95% interval for r
+	Significant     bool      `json:"significant"`
+}
+
+// Validate fills in the default overlap and checks the bounds
+func (o *CorrelationOptions) Validate() error {
+	if o.MinOverlap == 0 {
+		o.MinOverlap = DefaultCorrelationOverlap
+	}
+	if o.Lag < 0 || o.Lag > MaxCorrelationLag {
+		return errors.New("lag must be between 0 and 30 days")
+	}
+	if o.MinOverlap < MinCorrelationOverlap {
+		return errors.New("min_overlap must be at least 3 days")
+	}
+	return nil
+}
+
+// Correlate compares every pair of goals' daily completion from start to end,
+// counting days like BuildInsights. rates holds each goal's derived completion
+// per tracked day ("2006-01-02"). Pairs sharing fewer than MinOverlap days, or
+// where either side never varies, are left out.
+func Correlate(goals []Goal, rates map[uuid.UUID]map[string]float64, excuses map[uuid.UUID]Excuses, opts CorrelationOptions, start, end, today time.Time) *CorrelationResponse {
+	response := &CorrelationResponse{
+		StartDate:          start,
+		EndDate:            end,
+		CorrelationOptions: opts,
+		Pairs:              []GoalCorrelation{},
+	}
+
+	// Each goal's counted days, as offsets from start
+	days := int(dateOnly(end).Sub(dateOnly(start)).Hours()/24) + 1
+	if days <= 0 {
+		return response
+	}
+	series := make([][]float64, len(goals))
+	counted := make([][]bool, len(goals))
+	for i := range goals {
+		series[i] = make([]float64, days)
+		counted[i] = make([]bool, days)
+		for offset := range days {
+			day := dateOnly(start).AddDate(0, 0, offset)
+			series[i][offset], _, counted[i][offset] = goals[i].countedCompletion(day, today, rates[goals[i].ID], excuses[goals[i].ID])
+		}
+	}
+
+	for i := range goals {
+		for j := range goals {
+			// Without a lag the pair is symmetric, so each is compared once
+			if i == j || (opts.Lag == 0 && j < i) {
+				continue
+			}
+			var xs, ys []float64
+			for offset := 0; offset+opts.Lag < days; offset++ {
+				if counted[i][offset] && counted[j][offset+opts.Lag] {
+					xs = append(xs, series[i][offset])
+					ys = append(ys, series[j][offset+opts.Lag])
+				}
+			}
+			if len(xs) < opts.MinOverlap {
+				continue
+			}
+			r, ok := stats.Pearson(xs, ys)
+			if !ok {
+				continue
+			}
+
+			pair := GoalCorrelation{
+				GoalID:          goals[i].ID,
+				GoalTitle:       goals[i].Title,
+				LaggedGoalID:    goals[j].ID,
+				LaggedGoalTitle: goals[j].Title,
+				Overlap:         len(xs),
+				Coefficient:     r,
+				PValue:          stats.PearsonPValue(r, len(xs)),
+			}
+			pair.ConfidenceLow, pair.ConfidenceHigh = stats.FisherInterval(r, len(xs), 1.96)
+			pair.Significant = pair.PValue < CorrelationSignificance
+			response.Pairs = append(response.Pairs, pair)
+		}
+	}
+
+	sort.SliceStable(response.Pairs, func(i, j int) bool {
+		return math.Abs(response.Pairs[i].Coefficient) > math.Abs(response.Pairs[j].Coefficient)
+	})
+	return response
+}
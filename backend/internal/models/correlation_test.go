@@ -0,0 +1,80 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCorrelateLag(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 19)
+	newGoal := func(title string) Goal {
+		return Goal{ID: uuid.New(), Title: title, Type: GoalTypeQuantity, Target: 100, Direction: GoalDirectionAtLeast,
+			ScheduleType: ScheduleTypeDaily, IsActive: true, CreatedAt: start}
+	}
+	sleep, study, water := newGoal("Sleep"), newGoal("Study"), newGoal("Water")
+
+	// Study follows the day before's sleep, sleep is excused on two days, and
+	// water never varies
+	rates := map[uuid.UUID]map[string]float64{sleep.ID: {}, study.ID: {}, water.ID: {}}
+	excuses := map[uuid.UUID]Excuses{sleep.ID: {}}
+	for offset := range 20 {
+		key := DateKey(start.AddDate(0, 0, offset))
+		rate := float64(offset*37%101) / 101 * 100
+		if offset == 5 || offset == 11 {
+			excuses[sleep.ID][key] = ExcuseFreeze
+		} else {
+			rates[sleep.ID][key] = rate
+		}
+		rates[study.ID][DateKey(start.AddDate(0, 0, offset+1))] = rate
+		rates[water.ID][key] = 100
+	}
+	rates[study.ID][DateKey(start)] = 40
+	goals := []Goal{sleep, study, water}
+	today := end.AddDate(0, 0, 1)
+
+	type pair struct {
+		first, lagged uuid.UUID
+		overlap       int
+	}
+	tests := []struct {
+		name string
+		opts CorrelationOptions
+		want []pair
+	}{
+		// Unordered without a lag: 20 days less the two excused
+		{"no lag", CorrelationOptions{Lag: 0, MinOverlap: 3}, []pair{{sleep.ID, study.ID, 18}}},
+		// Both orders with a lag, over the 19 days that have a next day,
+		// less the excused days on the sleep side
+		{"a day's lag", CorrelationOptions{Lag: 1, MinOverlap: 3}, []pair{{sleep.ID, study.ID, 17}, {study.ID, sleep.ID, 17}}},
+		{"too little overlap", CorrelationOptions{Lag: 1, MinOverlap: 18}, []pair{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := Correlate(goals, rates, excuses, tt.opts, start, end, today)
+			if len(response.Pairs) != len(tt.want) {
+				t.Fatalf("got %d pairs, want %d: %+v", len(response.Pairs), len(tt.want), response.Pairs)
+			}
+			for i, want := range tt.want {
+				got := response.Pairs[i]
+				if got.GoalID != want.first || got.LaggedGoalID != want.lagged || got.Overlap != want.overlap {
+					t.Errorf("pair %d = %s after %s over %d days, want %s after %s over %d",
+						i, got.LaggedGoalTitle, got.GoalTitle, got.Overlap, want.lagged, want.first, want.overlap)
+				}
+			}
+		})
+	}
+
+	// Sleep against the next day's study is the same series
+	response := Correlate(goals, rates, excuses, CorrelationOptions{Lag: 1, MinOverlap: 3}, start, end, today)
+	first := response.Pairs[0]
+	if math.Abs(first.Coefficient-1) > 1e-9 || first.PValue > 1e-9 || !first.Significant || first.ConfidenceHigh < first.ConfidenceLow {
+		t.Errorf("lagged pair = %+v, want a significant r of 1", first)
+	}
+	if second := response.Pairs[1]; math.Abs(second.Coefficient) >= math.Abs(first.Coefficient) {
+		t.Errorf("pairs aren't ordered by strength: %v then %v", first.Coefficient, second.Coefficient)
+	}
+}
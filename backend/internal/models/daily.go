@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyProgress is a goal's derived value for one day, combined from all of
+// that day's entries with the goal's aggregation
+type DailyProgress struct {
+	GoalID         uuid.UUID `json:"goal_id"`
+	Date           time.Time `json:"date"`
+	Value          float64   `json:"value"`
+	CompletionRate float64   `json:"completion_rate"`
+	EntryCount     int       `json:"entry_count"`
+	Notes          string    `json:"notes,omitempty"` // Non-empty entry notes, oldest first
+}
+
+// DateKey identifies the calendar day a tracked date belongs to
+func DateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// DailyAggregation is how the goal combines several entries on one day. Goals
+// without an explicit choice use their metric's default.
+func (g *Goal) DailyAggregation() Aggregation {
+	if g.Aggregation.IsValid() {
+		return g.Aggregation
+	}
+	return g.Metric().Aggregation()
+}
+
+// ValidateAggregation accepts an empty aggregation, meaning the metric's default
+func (g *Goal) ValidateAggregation() error {
+	if g.Aggregation != "" && !g.Aggregation.IsValid() {
+		return fmt.Errorf("invalid aggregation %q", g.Aggregation)
+	}
+	return nil
+}
+
+// AggregateDaily groups a goal's entries by tracked day and derives each day's
+// value and completion. The result is ordered by date.
+func (g *Goal) AggregateDaily(entries []Progress) []DailyProgress {
+	byDay := make(map[string][]Progress)
+	for _, entry := range entries {
+		key := DateKey(entry.TrackedDate)
+		byDay[key] = append(byDay[key], entry)
+	}
+
+	days := make([]DailyProgress, 0, len(byDay))
+	for _, dayEntries := range byDay {
+		sort.SliceStable(dayEntries, func(i, j int) bool {
+			return dayEntries[i].LoggedAt.Before(dayEntries[j].LoggedAt)
+		})
+
+		values := make([]float64, len(dayEntries))
+		var notes []string
+		for i, entry := range dayEntries {
+			values[i] = entry.Value
+			if entry.Notes != "" {
+				notes = append(notes, entry.Notes)
+			}
+		}
+
+		value := g.DailyAggregation().Apply(values)
+		rate := g.CompletionRateFor(value)
+		if g.IsChecklist() && g.ChecklistItems != nil {
+			// Check-offs across the day's entries count once per item
+			done := make(map[uuid.UUID]bool)
+			for _, entry := range dayEntries {
+				for id := range entry.CheckedItems() {
+					done[id] = true
+				}
+			}
+			value = float64(len(done))
+			rate = g.ChecklistCompletion(dayEntries[0].TrackedDate, done)
+		}
+
+		days = append(days, DailyProgress{
+			GoalID:         g.ID,
+			Date:           dateOnly(dayEntries[0].TrackedDate),
+			Value:          value,
+			CompletionRate: rate,
+			EntryCount:     len(dayEntries),
+			Notes:          strings.Join(notes, "\n"),
+		})
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days
+}
+
+// Apply combines values ordered oldest to newest
+func (a Aggregation) Apply(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	switch a {
+	case AggregationMax:
+		max := values[0]
+		for _, v := range values[1:] {
+			if v > max {
+				max = v
+			}
+		}
+		return max
+	case AggregationLast:
+		return values[len(values)-1]
+	case AggregationAvg:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	}
+}
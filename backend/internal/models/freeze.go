@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExcuseKind says why a day doesn't count against a streak
+type ExcuseKind string
+
+const (
+	ExcuseFreeze   ExcuseKind = "freeze"   // Covered by a freeze token
+	ExcuseVacation ExcuseKind = "vacation" // Inside an excused period
+)
+
+type FreezeSource string
+
+const (
+	FreezeSourceEarned  FreezeSource = "earned"
+	FreezeSourceGranted FreezeSource = "granted"
+)
+
+const (
+	FreezeEarnEvery  = 30 // Streak days that earn one freeze token
+	MaxBankedFreezes = 3  // Unused tokens a user can hold at once
+	FreezeGraceDays  = 3  // How far back a missed day is still covered automatically
+)
+
+// FreezeToken protects a streak from one missed scheduled day. Tokens belong
+// to the user and are spent on whichever goal misses a day first.
+type FreezeToken struct {
+	ID           uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID       uuid.UUID    `json:"user_id" gorm:"type:uuid;not null;index"`
+	Source       FreezeSource `json:"source" gorm:"not null;size:10"`
+	EarnedGoalID *uuid.UUID   `json:"earned_goal_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_freeze_tokens_earned"` // Goal whose streak earned the token
+	EarnedOn     *time.Time   `json:"earned_on,omitempty" gorm:"type:date;uniqueIndex:idx_freeze_tokens_earned"`
+	UsedGoalID   *uuid.UUID   `json:"used_goal_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_freeze_tokens_used"`
+	UsedOn       *time.Time   `json:"used_on,omitempty" gorm:"type:date;uniqueIndex:idx_freeze_tokens_used"` // The missed day it covers
+	CreatedAt    time.Time    `json:"created_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (t *FreezeToken) IsUsed() bool {
+	return t.UsedOn != nil
+}
+
+// ExcusedPeriod is a vacation or pause window. Days inside it are neutral for
+// the goal, or for every goal when GoalID is nil.
+type ExcusedPeriod struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	GoalID    *uuid.UUID `json:"goal_id,omitempty" gorm:"type:uuid;index"`
+	StartDate time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate   time.Time  `json:"end_date" gorm:"type:date;not null"`
+	Reason    string     `json:"reason" gorm:"size:200"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	User User  `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Goal *Goal `json:"-" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+}
+
+type CreateExcusedPeriodRequest struct {
+	GoalIDs   []uuid.UUID `json:"goal_ids,omitempty"` // Empty excuses all goals
+	StartDate time.Time   `json:"start_date" validate:"required"`
+	EndDate   time.Time   `json:"end_date" validate:"required"`
+	Reason    string      `json:"reason" validate:"max=200"`
+}
+
+type FreezeBalance struct {
+	Available int           `json:"available"`
+	Used      int           `json:"used"`
+	MaxBanked int           `json:"max_banked"`
+	Tokens    []FreezeToken `json:"tokens"`
+}
+
+// Excuses maps dates ("2006-01-02") to the reason they are excused
+type Excuses map[string]ExcuseKind
+
+// Covers reports whether the period applies to the goal on the given day
+func (p *ExcusedPeriod) Covers(goalID uuid.UUID, date time.Time) bool {
+	if p.GoalID != nil && *p.GoalID != goalID {
+		return false
+	}
+	day := dateOnly(date)
+	return !day.Before(dateOnly(p.StartDate)) && !day.After(dateOnly(p.EndDate))
+}
+
+func (req *CreateExcusedPeriodRequest) Validate() error {
+	if req.EndDate.Before(req.StartDate) {
+		return errors.New("end date must not be before start date")
+	}
+	if daysBetween(req.StartDate, req.EndDate) > 366 {
+		return errors.New("excused periods can span at most a year")
+	}
+	if len(req.Reason) > 200 {
+		return errors.New("reason must be at most 200 characters")
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tarikozturk017/streak-map/backend/internal/palette"
+	"github.com/tarikozturk017/streak-map/backend/internal/streaks"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
 )
 
 type TrackingFrequency string
@@ -18,32 +22,67 @@ const (
 type GoalType string
 
 const (
-	GoalTypeTime     GoalType = "time"     // Hours, minutes (e.g., "2h 30m study")
-	GoalTypeQuantity GoalType = "quantity" // Pages, exercises, etc. (e.g., "10 pages read")
-	GoalTypeBoolean  GoalType = "boolean"  // Yes/No completion (e.g., "did workout")
-	GoalTypeDistance GoalType = "distance" // Kilometers, miles (e.g., "5km run")
+	GoalTypeTime      GoalType = "time"      // Hours, minutes (e.g., "2h 30m study")
+	GoalTypeQuantity  GoalType = "quantity"  // Pages, exercises, etc. (e.g., "10 pages read")
+	GoalTypeBoolean   GoalType = "boolean"   // Yes/No completion (e.g., "did workout")
+	GoalTypeDistance  GoalType = "distance"  // Kilometers, miles (e.g., "5km run")
+	GoalTypeRating    GoalType = "rating"    // Level on a scale (e.g., "mood 4/5")
+	GoalTypeCustom    GoalType = "custom"    // User-defined metric type (e.g., "glasses of water")
+	GoalTypeChecklist GoalType = "checklist" // Items done out of a list (e.g., "morning routine")
+)
+
+type GoalDirection string
+
+const (
+	GoalDirectionAtLeast GoalDirection = "at_least" // Reach the target or more (e.g., "read 10 pages")
+	GoalDirectionAtMost  GoalDirection = "at_most"  // Stay at or under the target (e.g., "under 2h screen time")
+	GoalDirectionRange   GoalDirection = "range"    // Land between Target and TargetMax (e.g., "7-9h sleep")
 )
 
 type Goal struct {
-	ID                uuid.UUID         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID            uuid.UUID         `json:"user_id" gorm:"type:uuid;not null;index"`
-	Title             string            `json:"title" gorm:"not null;size:255"`
-	Description       string            `json:"description" gorm:"type:text"`
-	Type              GoalType          `json:"type" gorm:"not null;default:'quantity'"`
-	ColorCode         string            `json:"color_code" gorm:"not null;size:7"` // Hex color like #FF0000
-	TrackingFrequency TrackingFrequency `json:"tracking_frequency" gorm:"not null;default:'daily'"`
-	Target            float64           `json:"target" gorm:"not null"`             // Target value in base units
-	Unit              string            `json:"unit" gorm:"size:50"`                // Unit label (pages, km, hours, etc.)
-	IsActive          bool              `json:"is_active" gorm:"default:true"`
-	GroupID           *uuid.UUID        `json:"group_id,omitempty" gorm:"type:uuid;index"` // For future grouping
-	SortOrder         int               `json:"sort_order" gorm:"default:0"`               // For ordering goals
-	CreatedAt         time.Time         `json:"created_at"`
-	UpdatedAt         time.Time         `json:"updated_at"`
+	ID                   uuid.UUID         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID               uuid.UUID         `json:"user_id" gorm:"type:uuid;not null;index"`
+	Title                string            `json:"title" gorm:"not null;size:255"`
+	Description          string            `json:"description" gorm:"type:text"`
+	Type                 GoalType          `json:"type" gorm:"not null;default:'quantity'"`
+	ColorCode            string            `json:"color_code" gorm:"not null;size:7"` // Hex color like #FF0000
+	TrackingFrequency    TrackingFrequency `json:"tracking_frequency" gorm:"not null;default:'daily'"`
+	Target               float64           `json:"target" gorm:"not null"`                                  // Target value in base units, in the goal's unit in JSON
+	Unit                 string            `json:"unit" gorm:"size:50"`                                     // Unit label (pages, km, hours, etc.)
+	MetricTypeID         *uuid.UUID        `json:"metric_type_id,omitempty" gorm:"type:uuid;index"`         // Definition for custom goals
+	Aggregation          Aggregation       `json:"aggregation,omitempty" gorm:"size:10"`                    // How a day's entries combine, defaults to the metric's
+	ScaleMin             int               `json:"scale_min" gorm:"not null;default:0"`                     // Lowest level, for rating goals
+	ScaleMax             int               `json:"scale_max" gorm:"not null;default:0"`                     // Highest level, for rating goals
+	ScaleLabels          []string          `json:"scale_labels,omitempty" gorm:"type:text;serializer:json"` // Optional label per level
+	ChecklistRule        ChecklistRule     `json:"checklist_rule,omitempty" gorm:"size:10"`                 // How checklist goals derive completion
+	Direction            GoalDirection     `json:"direction" gorm:"not null;default:'at_least'"`
+	TargetMax            *float64          `json:"target_max,omitempty"` // Upper bound for range goals, stored like Target
+	Mode                 GoalMode          `json:"mode" gorm:"not null;default:'recurring'"`
+	StartDate            *time.Time        `json:"start_date,omitempty" gorm:"type:date"`                   // Horizon start for cumulative goals
+	EndDate              *time.Time        `json:"end_date,omitempty" gorm:"type:date"`                     // Horizon end for cumulative goals, last day of a challenge
+	ChallengeDays        int               `json:"challenge_days,omitempty" gorm:"not null;default:0"`      // Challenge duration
+	ChallengePassDays    int               `json:"challenge_pass_days,omitempty" gorm:"not null;default:0"` // Successful days needed to pass, 0 for all
+	ScheduleType         ScheduleType      `json:"schedule_type" gorm:"not null;default:'daily'"`
+	ScheduleWeekdays     WeekdaySet        `json:"schedule_weekdays" gorm:"not null;default:0"`       // Used by weekday schedules
+	ScheduleInterval     int               `json:"schedule_interval" gorm:"not null;default:0"`       // Every N days, for interval schedules
+	ScheduleTimesPerWeek int               `json:"schedule_times_per_week" gorm:"not null;default:0"` // Weekly quota, for times_per_week schedules
+	IsActive             bool              `json:"is_active" gorm:"default:true"`
+	GroupID              *uuid.UUID        `json:"group_id,omitempty" gorm:"type:uuid;index"`          // For future grouping
+	SortOrder            int               `json:"sort_order" gorm:"default:0"`                        // For ordering goals
+	Weight               float64           `json:"weight" gorm:"not null;default:1"`                   // Priority in the daily score, 0 leaves the goal out
+	Palette              *palette.Palette  `json:"palette,omitempty" gorm:"type:text;serializer:json"` // Heatmap levels and colors, over the user's default
+	CreatedAt            time.Time         `json:"created_at"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+
+	TargetDisplay string `json:"target_display,omitempty" gorm:"-"` // Target rendered in the user's unit system
 
 	// Relationships
-	User       User        `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
-	Group      *GoalGroup  `json:"group,omitempty" gorm:"foreignKey:GroupID"`
-	Progresses []Progress  `json:"progresses,omitempty" gorm:"foreignKey:GoalID"`
+	User           User            `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Group          *GoalGroup      `json:"group,omitempty" gorm:"foreignKey:GroupID"`
+	MetricType     *MetricType     `json:"metric_type,omitempty" gorm:"foreignKey:MetricTypeID"`
+	Progresses     []Progress      `json:"progresses,omitempty" gorm:"foreignKey:GoalID"`
+	ChecklistItems []ChecklistItem `json:"checklist_items,omitempty" gorm:"foreignKey:GoalID"`
+	Tags           []Tag           `json:"tags,omitempty" gorm:"many2many:goal_tags;constraint:OnDelete:CASCADE"`
 }
 
 type GoalGroup struct {
@@ -64,26 +103,63 @@ type GoalGroup struct {
 type CreateGoalRequest struct {
 	Title             string            `json:"title" validate:"required,max=255"`
 	Description       string            `json:"description" validate:"max=1000"`
-	Type              GoalType          `json:"type" validate:"required,oneof=time quantity boolean distance"`
+	Type              GoalType          `json:"type" validate:"required,oneof=time quantity boolean distance rating custom checklist"`
 	ColorCode         string            `json:"color_code" validate:"required,hexcolor"`
 	TrackingFrequency TrackingFrequency `json:"tracking_frequency" validate:"required,oneof=daily weekly monthly"`
 	Target            float64           `json:"target" validate:"required,min=0"`
 	Unit              string            `json:"unit" validate:"required,max=50"`
+	MetricTypeID      *uuid.UUID        `json:"metric_type_id,omitempty"`
+	Aggregation       Aggregation       `json:"aggregation,omitempty" validate:"omitempty,oneof=sum max last avg"`
+	ScaleMin          int               `json:"scale_min"`
+	ScaleMax          int               `json:"scale_max"`
+	ScaleLabels       []string          `json:"scale_labels,omitempty"`
+	ChecklistRule     ChecklistRule     `json:"checklist_rule,omitempty" validate:"omitempty,oneof=fraction required"`
+	Direction         GoalDirection     `json:"direction" validate:"omitempty,oneof=at_least at_most range"`
+	TargetMax         *float64          `json:"target_max,omitempty" validate:"omitempty,min=0"`
+	Mode              GoalMode          `json:"mode" validate:"omitempty,oneof=recurring cumulative challenge"`
+	StartDate         *time.Time        `json:"start_date,omitempty"`
+	EndDate           *time.Time        `json:"end_date,omitempty"`
+	ChallengeDays     int               `json:"challenge_days" validate:"omitempty,min=1,max=366"`
+	ChallengePassDays int               `json:"challenge_pass_days" validate:"omitempty,min=0"`
 	GroupID           *uuid.UUID        `json:"group_id,omitempty"`
 	SortOrder         int               `json:"sort_order"`
+	Weight            *float64          `json:"weight,omitempty" validate:"omitempty,min=0,max=10"` // Defaults to 1
+	Palette           *palette.Palette  `json:"palette,omitempty"`
+	ScheduleRequest
+
+	ChecklistItems []CreateChecklistItemRequest `json:"checklist_items,omitempty"` // Initial items for checklist goals
+	TagIDs         []uuid.UUID                  `json:"tag_ids,omitempty"`
 }
 
 type UpdateGoalRequest struct {
 	Title             *string            `json:"title,omitempty" validate:"omitempty,max=255"`
 	Description       *string            `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Type              *GoalType          `json:"type,omitempty" validate:"omitempty,oneof=time quantity boolean distance"`
+	Type              *GoalType          `json:"type,omitempty" validate:"omitempty,oneof=time quantity boolean distance rating custom checklist"`
 	ColorCode         *string            `json:"color_code,omitempty" validate:"omitempty,hexcolor"`
 	TrackingFrequency *TrackingFrequency `json:"tracking_frequency,omitempty" validate:"omitempty,oneof=daily weekly monthly"`
 	Target            *float64           `json:"target,omitempty" validate:"omitempty,min=0"`
 	Unit              *string            `json:"unit,omitempty" validate:"omitempty,max=50"`
+	MetricTypeID      *uuid.UUID         `json:"metric_type_id,omitempty"`
+	Aggregation       *Aggregation       `json:"aggregation,omitempty" validate:"omitempty,oneof=sum max last avg"`
+	ScaleMin          *int               `json:"scale_min,omitempty"`
+	ScaleMax          *int               `json:"scale_max,omitempty"`
+	ScaleLabels       []string           `json:"scale_labels,omitempty"`
+	ChecklistRule     *ChecklistRule     `json:"checklist_rule,omitempty" validate:"omitempty,oneof=fraction required"`
+	Direction         *GoalDirection     `json:"direction,omitempty" validate:"omitempty,oneof=at_least at_most range"`
+	TargetMax         *float64           `json:"target_max,omitempty" validate:"omitempty,min=0"`
+	Mode              *GoalMode          `json:"mode,omitempty" validate:"omitempty,oneof=recurring cumulative challenge"`
+	StartDate         *time.Time         `json:"start_date,omitempty"`
+	EndDate           *time.Time         `json:"end_date,omitempty"`
+	ChallengeDays     *int               `json:"challenge_days,omitempty" validate:"omitempty,min=1,max=366"`
+	ChallengePassDays *int               `json:"challenge_pass_days,omitempty" validate:"omitempty,min=0"`
 	IsActive          *bool              `json:"is_active,omitempty"`
 	GroupID           *uuid.UUID         `json:"group_id,omitempty"`
 	SortOrder         *int               `json:"sort_order,omitempty"`
+	Weight            *float64           `json:"weight,omitempty" validate:"omitempty,min=0,max=10"`
+	Palette           *palette.Palette   `json:"palette,omitempty"` // An empty palette clears the goal's own
+	ScheduleRequest
+
+	TagIDs []uuid.UUID `json:"tag_ids,omitempty"` // Replaces the goal's tags when set
 }
 
 type CreateGoalGroupRequest struct {
@@ -108,68 +184,277 @@ func (tf TrackingFrequency) IsValid() bool {
 	return false
 }
 
+// StreakUnit follows the tracking frequency: weekly and monthly goals are
+// judged per period rather than per day
+func (g *Goal) StreakUnit() streaks.Unit {
+	switch g.TrackingFrequency {
+	case TrackingFrequencyWeekly:
+		return streaks.UnitWeek
+	case TrackingFrequencyMonthly:
+		return streaks.UnitMonth
+	}
+	return streaks.UnitDay
+}
+
+// StreakDefinition describes how the goal's streaks are judged, matching IsSuccess
+// and MissingEntryCompletion
+func (g *Goal) StreakDefinition() streaks.Definition {
+	def := streaks.Definition{
+		Unit:        g.StreakUnit(),
+		Direction:   streaks.AtLeast,
+		WeeklyQuota: g.WeeklyQuota(),
+		Scheduled:   func(day streaks.Date) bool { return g.IsScheduledOn(day.Time()) },
+	}
+	switch g.Direction {
+	case GoalDirectionAtMost:
+		def.Direction, def.Threshold = streaks.AtMost, 100
+	case GoalDirectionRange:
+		def.Direction, def.Threshold = streaks.Range, 100
+	}
+	def.AnyEntry = g.Type == GoalTypeRating
+	return def
+}
+
 func (gt GoalType) IsValid() bool {
 	switch gt {
-	case GoalTypeTime, GoalTypeQuantity, GoalTypeBoolean, GoalTypeDistance:
+	case GoalTypeTime, GoalTypeQuantity, GoalTypeBoolean, GoalTypeDistance, GoalTypeRating, GoalTypeCustom, GoalTypeChecklist:
 		return true
 	}
 	return false
 }
 
-func (g *Goal) ConvertInputToBaseUnit(input float64) float64 {
-	switch g.Type {
-	case GoalTypeTime:
-		// For time goals, input should be in minutes, target stored in minutes
-		return input
-	case GoalTypeQuantity, GoalTypeDistance:
-		// For quantity/distance, direct conversion
-		return input
-	case GoalTypeBoolean:
-		// For boolean goals, 1 = completed, 0 = not completed
-		if input > 0 {
-			return 1
+func (d GoalDirection) IsValid() bool {
+	switch d {
+	case GoalDirectionAtLeast, GoalDirectionAtMost, GoalDirectionRange:
+		return true
+	}
+	return false
+}
+
+func (g *Goal) ValidateDirection() error {
+	if !g.Direction.IsValid() {
+		return fmt.Errorf("invalid goal direction %q", g.Direction)
+	}
+	if g.Direction == GoalDirectionRange {
+		if g.TargetMax == nil {
+			return fmt.Errorf("range goals must specify target_max")
+		}
+		if *g.TargetMax < g.Target {
+			return fmt.Errorf("target_max must not be lower than target")
 		}
-		return 0
-	default:
-		return input
 	}
+	return nil
 }
 
-func (g *Goal) FormatTargetDisplay() string {
-	switch g.Type {
-	case GoalTypeTime:
-		hours := int(g.Target) / 60
-		minutes := int(g.Target) % 60
-		if hours > 0 && minutes > 0 {
-			return fmt.Sprintf("%dh %dm", hours, minutes)
-		} else if hours > 0 {
-			return fmt.Sprintf("%dh", hours)
+// CompletionRateFor converts a value into a completion percentage (0-100)
+// according to the goal's direction
+func (g *Goal) CompletionRateFor(value float64) float64 {
+	if g.Type == GoalTypeRating {
+		// Ratings are judged against their scale, not a target
+		return g.RatingScale().CompletionRate(value)
+	}
+
+	var rate float64
+	switch g.Direction {
+	case GoalDirectionAtMost:
+		// Staying at or under the limit is full success; going over decays towards 0
+		if value <= g.Target {
+			return 100
+		}
+		rate = (g.Target / value) * 100
+	case GoalDirectionRange:
+		upper := g.Target
+		if g.TargetMax != nil {
+			upper = *g.TargetMax
+		}
+		switch {
+		case value < g.Target:
+			rate = (value / g.Target) * 100
+		case value > upper:
+			rate = (upper / value) * 100
+		default:
+			return 100
 		}
-		return fmt.Sprintf("%dm", minutes)
-	case GoalTypeBoolean:
-		return "Complete"
 	default:
-		return fmt.Sprintf("%.1f %s", g.Target, g.Unit)
+		// Cumulative goals measure each day against the even daily pace
+		target := g.DailyTarget()
+		if target <= 0 {
+			return 0
+		}
+		rate = (value / target) * 100
 	}
+	if rate > 100 {
+		rate = 100
+	}
+	if rate < 0 {
+		rate = 0
+	}
+	return rate
 }
 
-func (g *Goal) FormatValueDisplay(value float64) string {
-	switch g.Type {
+// HeatmapPalette resolves the goal's palette over the user's default. The goal's
+// color is the end of the gradient unless the goal's own palette sets one.
+func (g *Goal) HeatmapPalette(userPalette *palette.Palette) palette.Palette {
+	return palette.Resolve(g.Palette, &palette.Palette{EndColor: g.ColorCode}, userPalette)
+}
+
+// ValidatePalette checks the goal's own palette and drops it when it is empty
+func (g *Goal) ValidatePalette() error {
+	if g.Palette.IsEmpty() {
+		g.Palette = nil
+		return nil
+	}
+	return g.Palette.Validate()
+}
+
+// IsSuccess reports whether a day with the given completion rate keeps a streak alive.
+// At-least goals count any progress, as they always have; limit and range goals
+// only count days that stayed within bounds. Rating goals track how a day went,
+// so any logged rating counts, the lowest included.
+func (g *Goal) IsSuccess(completionRate float64, logged bool) bool {
+	if g.Type == GoalTypeRating && logged {
+		return true
+	}
+	if g.Direction == GoalDirectionAtMost || g.Direction == GoalDirectionRange {
+		return completionRate >= 100
+	}
+	return completionRate > 0
+}
+
+// MissingEntryCompletion is the completion rate assumed for a past scheduled day
+// without an entry. Nothing logged against a limit means the limit was kept.
+func (g *Goal) MissingEntryCompletion() float64 {
+	if g.Direction == GoalDirectionAtMost {
+		return 100
+	}
+	return 0
+}
+
+// Dimension is the measurement dimension values of this goal type convert within
+func (gt GoalType) Dimension() units.Dimension {
+	switch gt {
 	case GoalTypeTime:
-		hours := int(value) / 60
-		minutes := int(value) % 60
-		if hours > 0 && minutes > 0 {
-			return fmt.Sprintf("%dh %dm", hours, minutes)
-		} else if hours > 0 {
-			return fmt.Sprintf("%dh", hours)
+		return units.DimensionTime
+	case GoalTypeDistance:
+		return units.DimensionDistance
+	}
+	return units.DimensionNone
+}
+
+// NormalizeUnit validates the goal's unit against its type and stores the
+// canonical unit name, defaulting to the base unit when none is given
+func (g *Goal) NormalizeUnit() error {
+	dimension := g.Type.Dimension()
+	if dimension == units.DimensionNone {
+		return nil
+	}
+	if g.Unit == "" {
+		base, _ := units.Base(dimension)
+		g.Unit = base.Name
+	}
+
+	unit, err := units.Lookup(g.Unit)
+	if err != nil || unit.Dimension != dimension {
+		return fmt.Errorf("unit %q is not valid for %s goals", g.Unit, g.Type)
+	}
+	g.Unit = unit.Name
+	return nil
+}
+
+// ToBaseUnit converts a target given in the goal's unit (e.g., 5 km) into the
+// base unit it is stored in (5000 meters). Dimensionless goals are unchanged.
+func (g *Goal) ToBaseUnit(value float64) float64 {
+	if g.Type.Dimension() == units.DimensionNone {
+		return value
+	}
+	if unit, err := units.Lookup(g.Unit); err == nil {
+		return value * unit.ToBase
+	}
+	return value
+}
+
+// FromBaseUnit converts a stored base-unit value back into the goal's unit
+func (g *Goal) FromBaseUnit(value float64) float64 {
+	if g.Type.Dimension() == units.DimensionNone {
+		return value
+	}
+	if converted, err := units.FromBase(value, g.Unit); err == nil {
+		return converted
+	}
+	return value
+}
+
+// ConvertInputToBaseUnit converts a logged value into the goal's base unit.
+// An empty unit means the value is in the goal's own unit; any other unit must
+// share the goal's dimension (e.g., miles against a km goal).
+func (g *Goal) ConvertInputToBaseUnit(input float64, unit string) (float64, error) {
+	switch g.Type {
+	case GoalTypeTime, GoalTypeDistance:
+		if unit == "" {
+			unit = g.Unit
+		}
+		if unit == "" {
+			// Goals created before units were tracked store plain base units
+			return input, nil
 		}
-		return fmt.Sprintf("%dm", minutes)
+		return units.ToBase(input, unit, g.Type.Dimension())
 	case GoalTypeBoolean:
-		if value > 0 {
-			return "Completed"
+		// For boolean goals, 1 = completed, 0 = not completed
+		if input > 0 {
+			return 1, nil
 		}
-		return "Not completed"
+		return 0, nil
 	default:
-		return fmt.Sprintf("%.1f %s", value, g.Unit)
+		if unit != "" && unit != g.Unit {
+			return 0, fmt.Errorf("unit %q does not match the goal's unit %q", unit, g.Unit)
+		}
+		return input, nil
+	}
+}
+
+// unitSystem is the system the goal's own unit belongs to
+func (g *Goal) unitSystem() units.System {
+	if unit, err := units.Lookup(g.Unit); err == nil && unit.System != "" {
+		return unit.System
+	}
+	return units.SystemMetric
+}
+
+func (g *Goal) FormatTargetDisplay() string {
+	return g.FormatTargetIn(g.unitSystem())
+}
+
+func (g *Goal) FormatValueDisplay(value float64) string {
+	return g.FormatValueIn(value, g.unitSystem())
+}
+
+// FormatTargetIn renders the target for the given unit system
+func (g *Goal) FormatTargetIn(system units.System) string {
+	if g.Type == GoalTypeBoolean {
+		return "Complete"
+	}
+	return g.FormatValueIn(g.Target, system)
+}
+
+// FormatValueIn renders a base-unit value for the given unit system
+func (g *Goal) FormatValueIn(value float64, system units.System) string {
+	return g.Metric().Format(value, system)
+}
+
+// Localize fills in the display fields for the user's preferred unit system
+func (g *Goal) Localize(system units.System) {
+	g.TargetDisplay = g.FormatTargetIn(system)
+}
+
+// MarshalJSON reports target and target_max in the goal's own unit, as clients
+// set them, rather than the base unit they are stored in
+func (g Goal) MarshalJSON() ([]byte, error) {
+	type goalJSON Goal
+	out := goalJSON(g)
+	out.Target = g.FromBaseUnit(g.Target)
+	if g.TargetMax != nil {
+		targetMax := g.FromBaseUnit(*g.TargetMax)
+		out.TargetMax = &targetMax
 	}
-}
\ No newline at end of file
+	return json.Marshal(out)
+}
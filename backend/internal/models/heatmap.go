@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HeatmapFilter selects the days and goals a heatmap covers
+type HeatmapFilter struct {
+	UserID    uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
+	GoalID    *uuid.UUID
+	GroupID   *uuid.UUID
+	ByTag     bool        // Only entries carrying one of TagIDs, directly or through their goal
+	TagIDs    []uuid.UUID // Empty with ByTag matches nothing
+}
+
+// DenseHeatmap has every date of the range, whether or not anything was
+// logged, laid out for a calendar of week columns
+type DenseHeatmap struct {
+	StartDate time.Time    `json:"start_date"`
+	EndDate   time.Time    `json:"end_date"`
+	Timezone  string       `json:"timezone"`   // The user's, which decides the dates
+	WeekStart string       `json:"week_start"` // Weekday of row 0
+	Weeks     int          `json:"weeks"`      // Columns needed for the range
+	Days      []HeatmapDay `json:"days"`
+}
+
+// HeatmapDay is one date with a cell per goal that has something to show on it
+type HeatmapDay struct {
+	Date      time.Time        `json:"date"`
+	DayOfWeek int              `json:"day_of_week"` // 0 is Sunday
+	Row       int              `json:"row"`         // Position in its week column, 0 being the week start
+	WeekIndex int              `json:"week_index"`  // Column, counted from the week holding the start date
+	Cells     []HeatmapData    `json:"cells"`       // Empty when nothing was logged or excused
+	Aggregate HeatmapAggregate `json:"aggregate"`
+}
+
+// HeatmapAggregate combines a day's goals, scored like the score series
+type HeatmapAggregate struct {
+	Score          float64 `json:"score"` // Weighted completion of the goals scheduled that day
+	IntensityLevel int     `json:"intensity_level"`
+	Color          string  `json:"color"`           // From the user's palette
+	ScheduledGoals int     `json:"scheduled_goals"` // Goal-days counted in the score
+	LoggedGoals    int     `json:"logged_goals"`    // Goals with entries
+	ExcusedGoals   int     `json:"excused_goals"`
+	EntryCount     int     `json:"entry_count"`
+}
@@ -0,0 +1,344 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Findings are only drawn from enough history to mean something
+const (
+	MinInsightDays    = 4   // Scheduled days a weekday or month needs
+	MinInsightMisses  = 2   // Misses on a weekday before it is called out
+	MinInsightEntries = 10  // Entries before the usual logging hour is called out
+	InsightMissRatio  = 1.5 // How much more often a weekday must be missed than the other days
+)
+
+type FindingKind string
+
+const (
+	FindingWeekdayMisses FindingKind = "weekday_misses" // A goal is missed more often on one weekday
+	FindingBestWeekday   FindingKind = "best_weekday"
+	FindingWorstWeekday  FindingKind = "worst_weekday"
+	FindingPeakHour      FindingKind = "peak_hour" // When entries are usually logged
+	FindingBestMonth     FindingKind = "best_month"
+)
+
+// InsightsResponse breaks consistency down by weekday, logging hour and month,
+// across the filtered goals and per goal, with the findings worth pointing out
+type InsightsResponse struct {
+	StartDate time.Time        `json:"start_date"`
+	EndDate   time.Time        `json:"end_date"`
+	Timezone  string           `json:"timezone"` // The user's, which logging hours are in
+	Weekdays  []WeekdayInsight `json:"weekdays"` // Sunday first
+	Hours     []HourInsight    `json:"hours"`    // One per hour of the day
+	Months    []MonthInsight   `json:"months"`
+	Goals     []GoalInsights   `json:"goals"`
+	Findings  []Finding        `json:"findings"`
+}
+
+// InsightBucket tallies scheduled goal-days. A day is completed when it keeps
+// the goal's streak alive and missed otherwise.
+type InsightBucket struct {
+	ScheduledDays     int     `json:"scheduled_days"`
+	CompletedDays     int     `json:"completed_days"`
+	MissedDays        int     `json:"missed_days"`
+	AverageCompletion float64 `json:"average_completion"`
+	ConsistencyRate   float64 `json:"consistency_rate"` // Completed share of scheduled days, 0-100
+
+	totalCompletion float64
+}
+
+type WeekdayInsight struct {
+	Weekday   string `json:"weekday"`
+	DayOfWeek int    `json:"day_of_week"` // 0 is Sunday
+	InsightBucket
+}
+
+type MonthInsight struct {
+	Month string `json:"month"` // "2006-01"
+	InsightBucket
+}
+
+// HourInsight covers the entries created in one hour of the day, in the user's time zone
+type HourInsight struct {
+	Hour              int     `json:"hour"`
+	EntryCount        int     `json:"entry_count"`
+	AverageCompletion float64 `json:"average_completion"` // Of the entries themselves
+
+	totalCompletion float64
+}
+
+type GoalInsights struct {
+	GoalID    uuid.UUID        `json:"goal_id"`
+	GoalTitle string           `json:"goal_title"`
+	Weekdays  []WeekdayInsight `json:"weekdays"` // Sunday first
+	InsightBucket
+}
+
+// Finding is an observation in plain language, e.g. "You miss Reading 3x more
+// often on Fridays"
+type Finding struct {
+	Kind    FindingKind `json:"kind"`
+	Message string      `json:"message"`
+	GoalID  *uuid.UUID  `json:"goal_id,omitempty"`
+	Weekday string      `json:"weekday,omitempty"`
+	Month   string      `json:"month,omitempty"`
+	Hour    *int        `json:"hour,omitempty"`
+	Ratio   float64     `json:"ratio,omitempty"` // How many times more often, for weekday misses
+
+	sortValue float64 // Orders goal findings, strongest first
+}
+
+func (b *InsightBucket) add(rate float64, success bool) {
+	b.ScheduledDays++
+	b.totalCompletion += rate
+	if success {
+		b.CompletedDays++
+	} else {
+		b.MissedDays++
+	}
+}
+
+func (b *InsightBucket) finish() {
+	if b.ScheduledDays == 0 {
+		return
+	}
+	b.AverageCompletion = b.totalCompletion / float64(b.ScheduledDays)
+	b.ConsistencyRate = float64(b.CompletedDays) / float64(b.ScheduledDays) * 100
+}
+
+// AddEntry counts an entry towards the hour it was created in
+func (h *HourInsight) AddEntry(completionRate float64) {
+	h.EntryCount++
+	h.totalCompletion += completionRate
+	h.AverageCompletion = h.totalCompletion / float64(h.EntryCount)
+}
+
+func newWeekdayInsights() []WeekdayInsight {
+	weekdays := make([]WeekdayInsight, 7)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays[day] = WeekdayInsight{Weekday: day.String(), DayOfWeek: int(day)}
+	}
+	return weekdays
+}
+
+// NewHourInsights returns an empty insight for every hour of the day
+func NewHourInsights() []HourInsight {
+	hours := make([]HourInsight, 24)
+	for hour := range hours {
+		hours[hour].Hour = hour
+	}
+	return hours
+}
+
+// BuildInsights tallies every day from start to end like DailyScores counts
+// them: a goal counts on the days it is scheduled within its lifetime, inactive
+// goals only where they have progress, and excused days are left out. Goals
+// with a weekly quota may skip any day, so only their logged days count, as do
+// today's until it is over. rates holds each goal's derived completion per
+// tracked day ("2006-01-02"), and hours the entries by the hour they were
+// created.
+func BuildInsights(goals []Goal, rates map[uuid.UUID]map[string]float64, excuses map[uuid.UUID]Excuses, hours []HourInsight, start, end, today time.Time) *InsightsResponse {
+	insights := &InsightsResponse{
+		StartDate: start,
+		EndDate:   end,
+		Weekdays:  newWeekdayInsights(),
+		Hours:     hours,
+		Months:    []MonthInsight{},
+		Goals:     make([]GoalInsights, len(goals)),
+		Findings:  []Finding{},
+	}
+	for i := range goals {
+		insights.Goals[i] = GoalInsights{GoalID: goals[i].ID, GoalTitle: goals[i].Title, Weekdays: newWeekdayInsights()}
+	}
+
+	last := dateOnly(end)
+	for day := dateOnly(start); !day.After(last); day = day.AddDate(0, 0, 1) {
+		month := day.Format("2006-01")
+		for i := range goals {
+			goal := &goals[i]
+			rate, logged, ok := goal.countedCompletion(day, today, rates[goal.ID], excuses[goal.ID])
+			if !ok {
+				continue
+			}
+
+			success := goal.IsSuccess(rate, logged)
+			goalInsights := &insights.Goals[i]
+			goalInsights.add(rate, success)
+			goalInsights.Weekdays[day.Weekday()].add(rate, success)
+			insights.Weekdays[day.Weekday()].add(rate, success)
+			if n := len(insights.Months); n == 0 || insights.Months[n-1].Month != month {
+				insights.Months = append(insights.Months, MonthInsight{Month: month})
+			}
+			insights.Months[len(insights.Months)-1].add(rate, success)
+		}
+	}
+
+	for i := range insights.Weekdays {
+		insights.Weekdays[i].finish()
+	}
+	for i := range insights.Months {
+		insights.Months[i].finish()
+	}
+	for i := range insights.Goals {
+		insights.Goals[i].finish()
+		for j := range insights.Goals[i].Weekdays {
+			insights.Goals[i].Weekdays[j].finish()
+		}
+	}
+	insights.Findings = insights.findings()
+	return insights
+}
+
+// countedCompletion is the goal's completion on a day for insights and
+// correlations, whether anything was logged, and false when the day doesn't
+// count. today and later days only count once something is logged.
+func (g *Goal) countedCompletion(day, today time.Time, rates map[string]float64, excuses Excuses) (rate float64, logged, counted bool) {
+	if !g.IsScheduledOn(day) {
+		return 0, false, false
+	}
+	key := DateKey(day)
+	if rate, logged := rates[key]; logged {
+		return rate, true, true
+	}
+	if !day.Before(dateOnly(today)) || !g.IsActive || !g.isLiveOn(day) || g.WeeklyQuota() > 0 {
+		return 0, false, false
+	}
+	if _, excused := excuses[key]; excused {
+		return 0, false, false
+	}
+	return g.MissingEntryCompletion(), false, true
+}
+
+// findings picks out the overall patterns first, then each goal's most missed
+// weekday, the starkest first
+func (in *InsightsResponse) findings() []Finding {
+	findings := []Finding{}
+
+	var best, worst *WeekdayInsight
+	for i := range in.Weekdays {
+		weekday := &in.Weekdays[i]
+		if weekday.ScheduledDays < MinInsightDays {
+			continue
+		}
+		if best == nil || weekday.ConsistencyRate > best.ConsistencyRate {
+			best = weekday
+		}
+		if worst == nil || weekday.ConsistencyRate < worst.ConsistencyRate {
+			worst = weekday
+		}
+	}
+	if best != nil && best.ConsistencyRate > worst.ConsistencyRate {
+		findings = append(findings, Finding{
+			Kind:    FindingBestWeekday,
+			Message: fmt.Sprintf("You're most consistent on %ss, completing %.0f%% of scheduled goals", best.Weekday, best.ConsistencyRate),
+			Weekday: best.Weekday,
+		}, Finding{
+			Kind:    FindingWorstWeekday,
+			Message: fmt.Sprintf("%ss are your least consistent day, with %.0f%% of scheduled goals completed", worst.Weekday, worst.ConsistencyRate),
+			Weekday: worst.Weekday,
+		})
+	}
+
+	var entries int
+	peak := -1
+	for _, hour := range in.Hours {
+		entries += hour.EntryCount
+		if hour.EntryCount > 0 && (peak < 0 || hour.EntryCount > in.Hours[peak].EntryCount) {
+			peak = hour.Hour
+		}
+	}
+	if entries >= MinInsightEntries && peak >= 0 {
+		findings = append(findings, Finding{
+			Kind: FindingPeakHour,
+			Message: fmt.Sprintf("You usually log between %02d:00 and %02d:00, when %.0f%% of your entries are made",
+				peak, (peak+1)%24, float64(in.Hours[peak].EntryCount)/float64(entries)*100),
+			Hour: &peak,
+		})
+	}
+
+	var bestMonth *MonthInsight
+	var months int
+	for i := range in.Months {
+		month := &in.Months[i]
+		if month.ScheduledDays < MinInsightDays {
+			continue
+		}
+		months++
+		if bestMonth == nil || month.ConsistencyRate > bestMonth.ConsistencyRate {
+			bestMonth = month
+		}
+	}
+	if months > 1 {
+		label := bestMonth.Month
+		if parsed, err := time.Parse("2006-01", bestMonth.Month); err == nil {
+			label = parsed.Format("January 2006")
+		}
+		findings = append(findings, Finding{
+			Kind:    FindingBestMonth,
+			Message: fmt.Sprintf("%s was your most consistent month, with %.0f%% of scheduled goals completed", label, bestMonth.ConsistencyRate),
+			Month:   bestMonth.Month,
+		})
+	}
+
+	var goalFindings []Finding
+	for i := range in.Goals {
+		if finding, ok := in.Goals[i].weekdayMisses(); ok {
+			goalFindings = append(goalFindings, finding)
+		}
+	}
+	sort.SliceStable(goalFindings, func(i, j int) bool { return goalFindings[i].sortValue > goalFindings[j].sortValue })
+	return append(findings, goalFindings...)
+}
+
+// weekdayMisses finds the weekday the goal is missed on most often compared to
+// its other scheduled days
+func (g *GoalInsights) weekdayMisses() (Finding, bool) {
+	var finding Finding
+	var found bool
+	for _, weekday := range g.Weekdays {
+		if weekday.ScheduledDays < MinInsightDays || weekday.MissedDays < MinInsightMisses {
+			continue
+		}
+		otherDays := g.ScheduledDays - weekday.ScheduledDays
+		if otherDays < MinInsightDays {
+			continue
+		}
+		missRate := float64(weekday.MissedDays) / float64(weekday.ScheduledDays)
+		otherRate := float64(g.MissedDays-weekday.MissedDays) / float64(otherDays)
+
+		var ratio float64
+		var message string
+		if otherRate == 0 {
+			// Never missed otherwise, so there's no ratio to give
+			ratio = math.Inf(1)
+			message = fmt.Sprintf("You only miss %s on %ss", g.GoalTitle, weekday.Weekday)
+		} else {
+			ratio = missRate / otherRate
+			message = fmt.Sprintf("You miss %s %sx more often on %ss",
+				g.GoalTitle, strconv.FormatFloat(math.Round(ratio*10)/10, 'f', -1, 64), weekday.Weekday)
+		}
+		if ratio < InsightMissRatio || (found && ratio <= finding.sortValue) {
+			continue
+		}
+
+		goalID := g.GoalID
+		finding = Finding{
+			Kind:      FindingWeekdayMisses,
+			Message:   message,
+			GoalID:    &goalID,
+			Weekday:   weekday.Weekday,
+			sortValue: ratio,
+		}
+		if !math.IsInf(ratio, 1) {
+			finding.Ratio = math.Round(ratio*10) / 10
+		}
+		found = true
+	}
+	return finding, found
+}
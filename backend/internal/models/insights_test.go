@@ -0,0 +1,169 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// readingGoal is a daily goal logged at 100 on every day from start to end
+// except the ones missed returns true for, which have nothing logged
+func readingGoal(start, end time.Time, missed func(time.Time) bool) (Goal, map[uuid.UUID]map[string]float64) {
+	goal := Goal{ID: uuid.New(), Title: "Reading", Type: GoalTypeBoolean, Target: 1, Direction: GoalDirectionAtLeast,
+		ScheduleType: ScheduleTypeDaily, IsActive: true, CreatedAt: start}
+	rates := map[string]float64{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !missed(day) {
+			rates[DateKey(day)] = 100
+		}
+	}
+	return goal, map[uuid.UUID]map[string]float64{goal.ID: rates}
+}
+
+func findingOf(findings []Finding, kind FindingKind) (Finding, bool) {
+	for _, finding := range findings {
+		if finding.Kind == kind {
+			return finding, true
+		}
+	}
+	return Finding{}, false
+}
+
+func TestBuildInsightsOnlyMissedOnFridays(t *testing.T) {
+	// Four weeks from Monday 2026-01-05, then an empty today
+	start, last, today := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+	goal, rates := readingGoal(start, last, func(day time.Time) bool { return day.Weekday() == time.Friday })
+
+	insights := BuildInsights([]Goal{goal}, rates, nil, NewHourInsights(), start, today, today)
+
+	reading := insights.Goals[0]
+	if reading.ScheduledDays != 28 || reading.MissedDays != 4 {
+		t.Errorf("scheduled %d, missed %d, want 28 and 4: an empty today isn't missed yet", reading.ScheduledDays, reading.MissedDays)
+	}
+	if friday := reading.Weekdays[time.Friday]; friday.ScheduledDays != 4 || friday.MissedDays != 4 || friday.ConsistencyRate != 0 {
+		t.Errorf("Friday = %+v, want 4 scheduled days, all missed", friday.InsightBucket)
+	}
+
+	finding, ok := findingOf(insights.Findings, FindingWeekdayMisses)
+	if !ok {
+		t.Fatalf("no weekday finding in %+v", insights.Findings)
+	}
+	if finding.Message != "You only miss Reading on Fridays" || finding.Weekday != "Friday" || finding.Ratio != 0 ||
+		finding.GoalID == nil || *finding.GoalID != goal.ID {
+		t.Errorf("finding = %+v, want Fridays without a ratio", finding)
+	}
+	if worst, ok := findingOf(insights.Findings, FindingWorstWeekday); !ok || worst.Weekday != "Friday" {
+		t.Errorf("worst weekday = %+v, want Friday", worst)
+	}
+	if best, ok := findingOf(insights.Findings, FindingBestWeekday); !ok || best.Weekday == "Friday" {
+		t.Errorf("best weekday = %+v", best)
+	}
+	if _, ok := findingOf(insights.Findings, FindingPeakHour); ok {
+		t.Error("peak hour found without any entries")
+	}
+}
+
+func TestBuildInsightsMissRatio(t *testing.T) {
+	// Three of four Fridays missed, and two of the other 24 days
+	start, end := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	missed := map[string]bool{"2026-01-09": true, "2026-01-16": true, "2026-01-23": true, "2026-01-06": true, "2026-01-20": true}
+	goal, rates := readingGoal(start, end, func(day time.Time) bool { return missed[DateKey(day)] })
+
+	insights := BuildInsights([]Goal{goal}, rates, nil, NewHourInsights(), start, end, end.AddDate(0, 0, 1))
+	finding, ok := findingOf(insights.Findings, FindingWeekdayMisses)
+	if !ok {
+		t.Fatalf("no weekday finding in %+v", insights.Findings)
+	}
+	// 3/4 against 2/24
+	if finding.Ratio != 9 || finding.Message != "You miss Reading 9x more often on Fridays" {
+		t.Errorf("finding = %+v, want Fridays 9x", finding)
+	}
+}
+
+// goalInsights builds a goal's tallies from scheduled and missed days per weekday
+func goalInsights(days map[time.Weekday][2]int) GoalInsights {
+	goal := GoalInsights{GoalID: uuid.New(), GoalTitle: "Reading", Weekdays: newWeekdayInsights()}
+	for weekday, counts := range days {
+		for i := range counts[0] {
+			success := i >= counts[1]
+			goal.Weekdays[weekday].add(100, success)
+			goal.add(100, success)
+		}
+	}
+	return goal
+}
+
+func TestWeekdayMisses(t *testing.T) {
+	tests := []struct {
+		name    string
+		days    map[time.Weekday][2]int // Scheduled and missed days
+		weekday string
+		ratio   float64
+	}{
+		{"too few scheduled days", map[time.Weekday][2]int{time.Friday: {MinInsightDays - 1, MinInsightDays - 1}, time.Monday: {8, 0}}, "", 0},
+		{"too few misses", map[time.Weekday][2]int{time.Friday: {4, MinInsightMisses - 1}, time.Monday: {8, 0}}, "", 0},
+		{"too few other days", map[time.Weekday][2]int{time.Friday: {4, 4}, time.Monday: {MinInsightDays - 1, 0}}, "", 0},
+		{"below the miss ratio", map[time.Weekday][2]int{time.Friday: {4, 2}, time.Monday: {8, 3}}, "", 0},
+		{"at the miss ratio", map[time.Weekday][2]int{time.Friday: {4, 3}, time.Monday: {8, 4}}, "Friday", InsightMissRatio},
+		{"never missed otherwise", map[time.Weekday][2]int{time.Friday: {4, 2}, time.Monday: {8, 0}}, "Friday", math.Inf(1)},
+		{"the starkest weekday", map[time.Weekday][2]int{time.Friday: {4, 3}, time.Sunday: {4, 4}, time.Monday: {8, 0}}, "Sunday", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := goalInsights(tt.days)
+			finding, ok := goal.weekdayMisses()
+			if ok != (tt.weekday != "") || finding.Weekday != tt.weekday {
+				t.Fatalf("weekdayMisses() = %+v, %t, want %q", finding, ok, tt.weekday)
+			}
+			if tt.ratio != 0 && finding.sortValue != tt.ratio {
+				t.Errorf("ratio = %v, want %v", finding.sortValue, tt.ratio)
+			}
+		})
+	}
+}
+
+func TestFindingsCutoffs(t *testing.T) {
+	in := &InsightsResponse{
+		Weekdays: newWeekdayInsights(),
+		Hours:    NewHourInsights(),
+		Months:   []MonthInsight{{Month: "2026-01"}, {Month: "2026-02"}},
+	}
+	for range MinInsightEntries - 1 {
+		in.Hours[7].AddEntry(100)
+	}
+	for range MinInsightDays {
+		in.Weekdays[time.Monday].add(100, true)
+		in.Months[0].add(100, true)
+	}
+	for range MinInsightDays - 1 {
+		in.Weekdays[time.Tuesday].add(0, false)
+		in.Months[1].add(0, false)
+	}
+	for i := range in.Weekdays {
+		in.Weekdays[i].finish()
+	}
+	for i := range in.Months {
+		in.Months[i].finish()
+	}
+	if findings := in.findings(); len(findings) != 0 {
+		t.Errorf("findings = %+v, want none below the cutoffs", findings)
+	}
+
+	in.Hours[7].AddEntry(100)
+	in.Weekdays[time.Tuesday].add(0, false)
+	in.Weekdays[time.Tuesday].finish()
+	in.Months[1].add(0, false)
+	in.Months[1].finish()
+	findings := in.findings()
+	for _, kind := range []FindingKind{FindingBestWeekday, FindingWorstWeekday, FindingPeakHour, FindingBestMonth} {
+		if _, ok := findingOf(findings, kind); !ok {
+			t.Errorf("no %s finding at the cutoffs in %+v", kind, findings)
+		}
+	}
+	if month, _ := findingOf(findings, FindingBestMonth); month.Month != "2026-01" {
+		t.Errorf("best month = %s, want 2026-01", month.Month)
+	}
+}
@@ -0,0 +1,247 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
+)
+
+type Aggregation string
+
+const (
+	AggregationSum  Aggregation = "sum"  // Add up entries (e.g., pages read across sessions)
+	AggregationMax  Aggregation = "max"  // Keep the best entry (e.g., longest plank)
+	AggregationLast Aggregation = "last" // Keep the latest entry (e.g., body weight)
+	AggregationAvg  Aggregation = "avg"  // Average the entries (e.g., mood check-ins)
+)
+
+func (a Aggregation) IsValid() bool {
+	switch a {
+	case AggregationSum, AggregationMax, AggregationLast, AggregationAvg:
+		return true
+	}
+	return false
+}
+
+// Metric defines how values of a goal are validated, displayed and combined.
+// Built-in goal types and user-defined metric types both implement it.
+type Metric interface {
+	Validate(value float64) error
+	Format(value float64, system units.System) string
+	Aggregation() Aggregation
+}
+
+// MetricType is a user-defined kind of measurement, e.g. "glasses of water" or "commits"
+type MetricType struct {
+	ID             uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID         uuid.UUID   `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_metric_types_user_name"`
+	Name           string      `json:"name" gorm:"not null;size:100;uniqueIndex:idx_metric_types_user_name"`
+	UnitLabel      string      `json:"unit_label" gorm:"not null;size:50"`                           // e.g., "glasses"
+	Precision      int         `json:"precision" gorm:"not null;default:0"`                          // Decimal places when displayed
+	MinValue       *float64    `json:"min_value,omitempty"`                                          // Lowest accepted entry
+	MaxValue       *float64    `json:"max_value,omitempty"`                                          // Highest accepted entry
+	Aggregate      Aggregation `json:"aggregation" gorm:"column:aggregation;not null;default:'sum'"` // How a day's entries combine
+	FormatTemplate string      `json:"format_template" gorm:"size:100"`                              // e.g., "{value} {unit}", "{value} sets"
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type CreateMetricTypeRequest struct {
+	Name           string      `json:"name" validate:"required,max=100"`
+	UnitLabel      string      `json:"unit_label" validate:"required,max=50"`
+	Precision      int         `json:"precision" validate:"min=0,max=6"`
+	MinValue       *float64    `json:"min_value,omitempty"`
+	MaxValue       *float64    `json:"max_value,omitempty"`
+	Aggregation    Aggregation `json:"aggregation" validate:"omitempty,oneof=sum max last avg"`
+	FormatTemplate string      `json:"format_template" validate:"max=100"`
+}
+
+type UpdateMetricTypeRequest struct {
+	Name           *string       `json:"name,omitempty" validate:"omitempty,max=100"`
+	UnitLabel      *string       `json:"unit_label,omitempty" validate:"omitempty,max=50"`
+	Precision      *int          `json:"precision,omitempty" validate:"omitempty,min=0,max=6"`
+	MinValue       OptionalFloat `json:"min_value"`
+	MaxValue       OptionalFloat `json:"max_value"`
+	Aggregation    *Aggregation  `json:"aggregation,omitempty" validate:"omitempty,oneof=sum max last avg"`
+	FormatTemplate *string       `json:"format_template,omitempty" validate:"omitempty,max=100"`
+}
+
+// OptionalFloat tells a field left out of a request apart from an explicit
+// null, which clears it
+type OptionalFloat struct {
+	Set   bool
+	Value *float64
+}
+
+func (o *OptionalFloat) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+const DefaultFormatTemplate = "{value} {unit}"
+
+func (m *MetricType) ValidateDefinition() error {
+	if strings.TrimSpace(m.Name) == "" {
+		return errors.New("metric type name is required")
+	}
+	if strings.TrimSpace(m.UnitLabel) == "" {
+		return errors.New("metric type unit label is required")
+	}
+	if m.Precision < 0 || m.Precision > 6 {
+		return errors.New("precision must be between 0 and 6")
+	}
+	if m.MinValue != nil && m.MaxValue != nil && *m.MaxValue < *m.MinValue {
+		return errors.New("max_value must not be lower than min_value")
+	}
+	if m.Aggregate == "" {
+		m.Aggregate = AggregationSum
+	}
+	if !m.Aggregate.IsValid() {
+		return fmt.Errorf("invalid aggregation %q", m.Aggregate)
+	}
+	if m.FormatTemplate == "" {
+		m.FormatTemplate = DefaultFormatTemplate
+	}
+	if !strings.Contains(m.FormatTemplate, "{value}") {
+		return errors.New("format template must contain {value}")
+	}
+	return nil
+}
+
+func (m *MetricType) Validate(value float64) error {
+	if m.MinValue != nil && value < *m.MinValue {
+		return fmt.Errorf("%s value must be at least %s", m.Name, m.formatNumber(*m.MinValue))
+	}
+	if m.MaxValue != nil && value > *m.MaxValue {
+		return fmt.Errorf("%s value must be at most %s", m.Name, m.formatNumber(*m.MaxValue))
+	}
+	return nil
+}
+
+func (m *MetricType) Format(value float64, _ units.System) string {
+	template := m.FormatTemplate
+	if template == "" {
+		template = DefaultFormatTemplate
+	}
+	return strings.NewReplacer("{value}", m.formatNumber(value), "{unit}", m.UnitLabel).Replace(template)
+}
+
+func (m *MetricType) Aggregation() Aggregation {
+	return m.Aggregate
+}
+
+func (m *MetricType) formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', m.Precision, 64)
+}
+
+// Built-in metrics for the fixed goal types
+
+type timeMetric struct{}
+
+func (timeMetric) Validate(value float64) error {
+	if value < 0 || value > 24*60 { // Max 24 hours in minutes
+		return errors.New("time value must be between 0 and 1440 minutes (24 hours)")
+	}
+	return nil
+}
+
+func (timeMetric) Format(value float64, _ units.System) string {
+	return units.FormatDuration(value)
+}
+
+func (timeMetric) Aggregation() Aggregation { return AggregationSum }
+
+type distanceMetric struct{}
+
+func (distanceMetric) Validate(value float64) error {
+	if value < 0 {
+		return errors.New("distance value must be non-negative")
+	}
+	return nil
+}
+
+func (distanceMetric) Format(value float64, system units.System) string {
+	return units.Format(value, units.DimensionDistance, system)
+}
+
+func (distanceMetric) Aggregation() Aggregation { return AggregationSum }
+
+type quantityMetric struct {
+	unit string
+}
+
+func (quantityMetric) Validate(value float64) error {
+	if value < 0 {
+		return errors.New("quantity value must be non-negative")
+	}
+	return nil
+}
+
+func (m quantityMetric) Format(value float64, _ units.System) string {
+	return fmt.Sprintf("%.1f %s", value, m.unit)
+}
+
+func (quantityMetric) Aggregation() Aggregation { return AggregationSum }
+
+type booleanMetric struct{}
+
+func (booleanMetric) Validate(value float64) error {
+	if value != 0 && value != 1 {
+		return errors.New("boolean value must be 0 or 1")
+	}
+	return nil
+}
+
+func (booleanMetric) Format(value float64, _ units.System) string {
+	if value > 0 {
+		return "Completed"
+	}
+	return "Not completed"
+}
+
+func (booleanMetric) Aggregation() Aggregation { return AggregationMax }
+
+type ratingMetric struct {
+	scale RatingScale
+}
+
+func (m ratingMetric) Validate(value float64) error {
+	return m.scale.Validate(value)
+}
+
+func (m ratingMetric) Format(value float64, _ units.System) string {
+	return m.scale.Format(value)
+}
+
+func (ratingMetric) Aggregation() Aggregation { return AggregationAvg }
+
+// Metric returns the definition values of this goal follow. Custom goals use
+// their metric type; a custom goal whose type wasn't loaded falls back to a
+// plain quantity with the goal's unit label.
+func (g *Goal) Metric() Metric {
+	switch g.Type {
+	case GoalTypeTime:
+		return timeMetric{}
+	case GoalTypeDistance:
+		return distanceMetric{}
+	case GoalTypeBoolean:
+		return booleanMetric{}
+	case GoalTypeRating:
+		return ratingMetric{scale: g.RatingScale()}
+	case GoalTypeChecklist:
+		return checklistMetric{}
+	case GoalTypeCustom:
+		if g.MetricType != nil {
+			return g.MetricType
+		}
+	}
+	return quantityMetric{unit: g.Unit}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUpdateMetricTypeRequestBounds(t *testing.T) {
+	cases := []struct {
+		body    string
+		wantSet bool
+		wantMin *float64
+	}{
+		{`{}`, false, nil},
+		{`{"min_value": null}`, true, nil},
+		{`{"min_value": 2.5}`, true, func() *float64 { v := 2.5; return &v }()},
+	}
+	for _, c := range cases {
+		var req UpdateMetricTypeRequest
+		if err := json.Unmarshal([]byte(c.body), &req); err != nil {
+			t.Fatalf("%s: %v", c.body, err)
+		}
+		got := req.MinValue
+		if got.Set != c.wantSet || (got.Value == nil) != (c.wantMin == nil) || (got.Value != nil && *got.Value != *c.wantMin) {
+			t.Errorf("%s: min_value = %+v, want set %t value %v", c.body, got, c.wantSet, c.wantMin)
+		}
+		if req.MaxValue.Set {
+			t.Errorf("%s: max_value set without being sent", c.body)
+		}
+	}
+}
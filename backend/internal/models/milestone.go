@@ -0,0 +1,195 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GoalMode string
+
+const (
+	GoalModeRecurring  GoalMode = "recurring"  // Target applies to every tracked day (the default)
+	GoalModeCumulative GoalMode = "cumulative" // Target is a running total over StartDate..EndDate (e.g., "1000 km this year")
+	GoalModeChallenge  GoalMode = "challenge"  // Fixed-length run of days with a pass/fail rule (e.g., "30 days of meditation")
+)
+
+func (m GoalMode) IsValid() bool {
+	switch m {
+	case GoalModeRecurring, GoalModeCumulative, GoalModeChallenge:
+		return true
+	}
+	return false
+}
+
+type MilestonePoint struct {
+	Date         time.Time `json:"date"`
+	Value        float64   `json:"value"`         // Contribution logged on this day
+	RunningTotal float64   `json:"running_total"` // Total up to and including this day
+}
+
+type MilestoneStatus struct {
+	GoalID              uuid.UUID        `json:"goal_id"`
+	GoalTitle           string           `json:"goal_title"`
+	StartDate           time.Time        `json:"start_date"`
+	EndDate             time.Time        `json:"end_date"`
+	Target              float64          `json:"target"`
+	Total               float64          `json:"total"`
+	Remaining           float64          `json:"remaining"`
+	PercentComplete     float64          `json:"percent_complete"`
+	TotalDays           int              `json:"total_days"`
+	DaysElapsed         int              `json:"days_elapsed"`
+	DaysRemaining       int              `json:"days_remaining"`
+	PlannedDailyPace    float64          `json:"planned_daily_pace"`  // Target spread evenly over the horizon
+	RequiredDailyPace   float64          `json:"required_daily_pace"` // What's needed per remaining day to finish on time
+	ActualDailyPace     float64          `json:"actual_daily_pace"`   // Average per elapsed day so far
+	ProjectedFinishDate *time.Time       `json:"projected_finish_date,omitempty"`
+	OnTrack             bool             `json:"on_track"`
+	FormattedTotal      string           `json:"formatted_total"`
+	FormattedTarget     string           `json:"formatted_target"`
+	RunningTotals       []MilestonePoint `json:"running_totals"`
+}
+
+func (g *Goal) IsCumulative() bool {
+	return g.Mode == GoalModeCumulative
+}
+
+// ValidateMode checks the date range a goal's mode depends on. Cumulative goals
+// without explicit dates default to the current calendar year.
+func (g *Goal) ValidateMode() error {
+	if g.Mode == "" {
+		g.Mode = GoalModeRecurring
+	}
+	if !g.Mode.IsValid() {
+		return fmt.Errorf("invalid goal mode %q", g.Mode)
+	}
+
+	if g.IsCumulative() {
+		if g.StartDate == nil && g.EndDate == nil {
+			year := time.Now().Year()
+			start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+			end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+			g.StartDate, g.EndDate = &start, &end
+		}
+		if g.StartDate == nil || g.EndDate == nil {
+			return fmt.Errorf("cumulative goals need both start_date and end_date")
+		}
+		if g.Direction != GoalDirectionAtLeast {
+			return fmt.Errorf("cumulative goals only support the at_least direction")
+		}
+	}
+	if g.IsChallenge() {
+		if err := g.validateChallenge(); err != nil {
+			return err
+		}
+	}
+	if g.StartDate != nil && g.EndDate != nil && g.EndDate.Before(*g.StartDate) {
+		return fmt.Errorf("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// HorizonDays is the number of days in the goal's date range, inclusive
+func (g *Goal) HorizonDays() int {
+	if g.StartDate == nil || g.EndDate == nil {
+		return 0
+	}
+	return daysBetween(dateOnly(*g.StartDate), dateOnly(*g.EndDate)) + 1
+}
+
+// DailyTarget is the amount a single day is measured against. For cumulative
+// goals that's the target spread evenly across the horizon.
+func (g *Goal) DailyTarget() float64 {
+	if g.IsCumulative() {
+		if days := g.HorizonDays(); days > 0 {
+			return g.Target / float64(days)
+		}
+	}
+	return g.Target
+}
+
+// MilestoneStatus summarises a cumulative goal as of the given day, from the
+// per-day contributions ordered by date
+func (g *Goal) MilestoneStatus(daily []MilestonePoint, asOf time.Time) MilestoneStatus {
+	status := MilestoneStatus{
+		GoalID:           g.ID,
+		GoalTitle:        g.Title,
+		Target:           g.Target,
+		TotalDays:        g.HorizonDays(),
+		PlannedDailyPace: g.DailyTarget(),
+		FormattedTarget:  g.FormatValueDisplay(g.Target),
+		RunningTotals:    make([]MilestonePoint, 0, len(daily)),
+	}
+	if g.StartDate == nil || g.EndDate == nil {
+		return status.inGoalUnit(g)
+	}
+	start, end := dateOnly(*g.StartDate), dateOnly(*g.EndDate)
+	status.StartDate, status.EndDate = start, end
+
+	for _, point := range daily {
+		status.Total += point.Value
+		point.RunningTotal = status.Total
+		status.RunningTotals = append(status.RunningTotals, point)
+	}
+
+	today := dateOnly(asOf)
+	switch {
+	case today.Before(start):
+		status.DaysElapsed = 0
+	case today.After(end):
+		status.DaysElapsed = status.TotalDays
+	default:
+		status.DaysElapsed = daysBetween(start, today) + 1
+	}
+	status.DaysRemaining = status.TotalDays - status.DaysElapsed
+
+	status.Remaining = math.Max(g.Target-status.Total, 0)
+	if g.Target > 0 {
+		status.PercentComplete = math.Min(status.Total/g.Target*100, 100)
+	}
+	if status.DaysElapsed > 0 {
+		status.ActualDailyPace = status.Total / float64(status.DaysElapsed)
+	}
+	if status.DaysRemaining > 0 {
+		status.RequiredDailyPace = status.Remaining / float64(status.DaysRemaining)
+	}
+
+	switch {
+	case status.Remaining == 0:
+		// Already reached: the finish date is the day the running total crossed the target
+		for _, point := range status.RunningTotals {
+			if point.RunningTotal >= g.Target {
+				finished := dateOnly(point.Date)
+				status.ProjectedFinishDate = &finished
+				break
+			}
+		}
+	case status.ActualDailyPace > 0:
+		daysNeeded := int(math.Ceil(status.Remaining / status.ActualDailyPace))
+		projected := today.AddDate(0, 0, daysNeeded)
+		status.ProjectedFinishDate = &projected
+	}
+
+	expected := status.PlannedDailyPace * float64(status.DaysElapsed)
+	status.OnTrack = status.Remaining == 0 || status.Total >= expected
+	status.FormattedTotal = g.FormatValueDisplay(status.Total)
+	return status.inGoalUnit(g)
+}
+
+// inGoalUnit converts the amounts, worked out in base units, into the goal's
+// own unit, like its target is shown
+func (s MilestoneStatus) inGoalUnit(g *Goal) MilestoneStatus {
+	s.Target = g.FromBaseUnit(s.Target)
+	s.Total = g.FromBaseUnit(s.Total)
+	s.Remaining = g.FromBaseUnit(s.Remaining)
+	s.PlannedDailyPace = g.FromBaseUnit(s.PlannedDailyPace)
+	s.RequiredDailyPace = g.FromBaseUnit(s.RequiredDailyPace)
+	s.ActualDailyPace = g.FromBaseUnit(s.ActualDailyPace)
+	for i := range s.RunningTotals {
+		s.RunningTotals[i].Value = g.FromBaseUnit(s.RunningTotals[i].Value)
+		s.RunningTotals[i].RunningTotal = g.FromBaseUnit(s.RunningTotals[i].RunningTotal)
+	}
+	return s
+}
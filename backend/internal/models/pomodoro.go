@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PomodoroInterval is a finished focus interval. Completed ones are also
+// credited to the goal as time progress; interrupted ones are only kept here.
+type PomodoroInterval struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	GoalID         uuid.UUID  `json:"goal_id" gorm:"type:uuid;not null;index"`
+	ProgressID     *uuid.UUID `json:"progress_id,omitempty" gorm:"type:uuid"` // Entry the interval was credited as
+	StartedAt      time.Time  `json:"started_at" gorm:"not null"`
+	EndedAt        time.Time  `json:"ended_at" gorm:"not null"`
+	TrackedDate    time.Time  `json:"tracked_date" gorm:"type:date;not null;index"` // User's local day the interval started on
+	PlannedMinutes float64    `json:"planned_minutes" gorm:"not null"`
+	ActualMinutes  float64    `json:"actual_minutes" gorm:"not null"`
+	Completed      bool       `json:"completed" gorm:"not null"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Relationships
+	Goal Goal `json:"-" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// StartPomodoroRequest lengths are in minutes; zero values use the defaults (25/5/15, 4 cycles)
+type StartPomodoroRequest struct {
+	FocusMinutes      int `json:"focus_minutes" validate:"omitempty,min=1,max=240"`
+	ShortBreakMinutes int `json:"short_break_minutes" validate:"omitempty,min=1,max=60"`
+	LongBreakMinutes  int `json:"long_break_minutes" validate:"omitempty,min=1,max=120"`
+	Cycles            int `json:"cycles" validate:"omitempty,min=1,max=12"`
+}
+
+type PomodoroDayStats struct {
+	Date         time.Time `json:"date"`
+	Completed    int       `json:"completed"`
+	Interrupted  int       `json:"interrupted"`
+	FocusMinutes float64   `json:"focus_minutes"` // Credited minutes from completed intervals
+}
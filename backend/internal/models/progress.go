@@ -1,10 +1,12 @@
 package models
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tarikozturk017/streak-map/backend/internal/palette"
+	"github.com/tarikozturk017/streak-map/backend/internal/streaks"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
 )
 
 type Progress struct {
@@ -15,33 +17,49 @@ type Progress struct {
 	CompletionRate   float64   `json:"completion_rate" gorm:"not null"`         // Calculated percentage (value/target * 100)
 	Notes            string    `json:"notes" gorm:"type:text"`                  // Optional notes for this entry
 	TrackedDate      time.Time `json:"tracked_date" gorm:"not null;index"`      // The date this progress represents
+	LoggedAt         time.Time `json:"logged_at" gorm:"not null;default:now()"` // When this entry happened; a day can have several
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 
+	ValueDisplay string `json:"value_display,omitempty" gorm:"-"` // Value rendered in the user's unit system
+
 	// Relationships
-	Goal Goal `json:"goal,omitempty" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
-	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Goal   Goal             `json:"goal,omitempty" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+	User   User             `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Checks []ChecklistCheck `json:"checks,omitempty" gorm:"foreignKey:ProgressID"` // Items done, for checklist goals
+	Tags   []Tag            `json:"tags,omitempty" gorm:"many2many:progress_tags;constraint:OnDelete:CASCADE"`
 }
 
 type CreateProgressRequest struct {
-	GoalID      uuid.UUID `json:"goal_id" validate:"required"`
-	Value       float64   `json:"value" validate:"required,min=0"`
-	Notes       string    `json:"notes" validate:"max=1000"`
-	TrackedDate time.Time `json:"tracked_date" validate:"required"`
+	GoalID      uuid.UUID  `json:"goal_id" validate:"required"`
+	Value       float64    `json:"value" validate:"required,min=0"`
+	Unit        string     `json:"unit,omitempty" validate:"max=50"` // Defaults to the goal's unit
+	Notes       string     `json:"notes" validate:"max=1000"`
+	TrackedDate time.Time  `json:"tracked_date" validate:"required"`
+	LoggedAt    *time.Time `json:"logged_at,omitempty"` // Defaults to now
+
+	CheckedItemIDs []uuid.UUID `json:"checked_item_ids,omitempty"` // Items done, for checklist goals; replaces Value
+	TagIDs         []uuid.UUID `json:"tag_ids,omitempty"`
 }
 
 type CreateProgressTimeRequest struct {
-	GoalID      uuid.UUID `json:"goal_id" validate:"required"`
-	Hours       int       `json:"hours" validate:"min=0,max=23"`
-	Minutes     int       `json:"minutes" validate:"min=0,max=59"`
-	Notes       string    `json:"notes" validate:"max=1000"`
-	TrackedDate time.Time `json:"tracked_date" validate:"required"`
+	GoalID      uuid.UUID  `json:"goal_id" validate:"required"`
+	Hours       int        `json:"hours" validate:"min=0,max=23"`
+	Minutes     int        `json:"minutes" validate:"min=0,max=59"`
+	Notes       string     `json:"notes" validate:"max=1000"`
+	TrackedDate time.Time  `json:"tracked_date" validate:"required"`
+	LoggedAt    *time.Time `json:"logged_at,omitempty"` // Defaults to now
 }
 
 type UpdateProgressRequest struct {
 	Value       *float64   `json:"value,omitempty" validate:"omitempty,min=0"`
+	Unit        string     `json:"unit,omitempty" validate:"max=50"` // Unit of Value, defaults to the goal's unit
 	Notes       *string    `json:"notes,omitempty" validate:"omitempty,max=1000"`
 	TrackedDate *time.Time `json:"tracked_date,omitempty"`
+	LoggedAt    *time.Time `json:"logged_at,omitempty"`
+
+	CheckedItemIDs []uuid.UUID `json:"checked_item_ids,omitempty"` // Replaces the entry's check-offs when set
+	TagIDs         []uuid.UUID `json:"tag_ids,omitempty"`          // Replaces the entry's tags when set
 }
 
 type ProgressFilter struct {
@@ -57,79 +75,90 @@ type ProgressSummary struct {
 	GoalTitle          string    `json:"goal_title"`
 	GoalType           GoalType  `json:"goal_type"`
 	TotalEntries       int       `json:"total_entries"`
+	TrackedDays        int       `json:"tracked_days"` // Distinct days with entries
 	AverageCompletion  float64   `json:"average_completion"`
 	BestCompletion     float64   `json:"best_completion"`
 	CurrentStreak      int       `json:"current_streak"`
 	LongestStreak      int       `json:"longest_streak"`
+	StreakUnit         streaks.Unit `json:"streak_unit"` // Streaks count days, or weeks and months for those frequencies
+	MissedDays         int       `json:"missed_days"` // Scheduled days without progress, in streak units
+	ExcusedDays        int       `json:"excused_days"` // Frozen or vacation days, which don't break streaks
 	LastTrackedDate    time.Time `json:"last_tracked_date"`
+	StreakHistory      []streaks.Interval `json:"streak_history,omitempty"` // Every run up to the end date, the current one last
+}
+
+// OverallSummary combines the summaries of several goals over one range
+type OverallSummary struct {
+	StartDate         time.Time         `json:"start_date"`
+	EndDate           time.Time         `json:"end_date"`
+	GoalCount         int               `json:"goal_count"`
+	TotalEntries      int               `json:"total_entries"`
+	TrackedDays       int               `json:"tracked_days"`       // Goal-days with entries
+	AverageCompletion float64           `json:"average_completion"` // Over those goal-days
+	BestCurrentStreak int               `json:"best_current_streak"`
+	LongestStreak     int               `json:"longest_streak"`
+	Goals             []ProgressSummary `json:"goals"`
 }
 
 type HeatmapData struct {
-	Date           time.Time `json:"date"`
-	CompletionRate float64   `json:"completion_rate"`
-	Value          float64   `json:"value"`
-	GoalTitle      string    `json:"goal_title"`
-	GoalType       GoalType  `json:"goal_type"`
-	ColorCode      string    `json:"color_code"`
-	Unit           string    `json:"unit"`
-	Notes          string    `json:"notes,omitempty"`
-	FormattedValue string    `json:"formatted_value"`
+	Date           time.Time     `json:"date"`
+	GoalID         uuid.UUID     `json:"goal_id"`
+	IsScheduled    bool          `json:"is_scheduled"`      // False on the goal's rest days, which are neutral
+	Excused        ExcuseKind    `json:"excused,omitempty"` // Set on frozen or vacation days
+	CompletionRate float64       `json:"completion_rate"`
+	IntensityLevel int           `json:"intensity_level"` // 0-N on the goal's palette, following the goal's direction
+	Color          string        `json:"color"`           // Resolved from the goal's palette
+	EntryCount     int           `json:"entry_count"`     // Entries combined into this day's value
+	GoalDirection  GoalDirection `json:"goal_direction"`
+	Value          float64       `json:"value"`
+	GoalTitle      string        `json:"goal_title"`
+	GoalType       GoalType      `json:"goal_type"`
+	ColorCode      string        `json:"color_code"`
+	Unit           string        `json:"unit"`
+	Notes          string        `json:"notes,omitempty"`
+	FormattedValue string        `json:"formatted_value"`
 }
 
 type ProgressResponse struct {
-	Progress Progress `json:"progress"`
-	Goal     Goal     `json:"goal"`
+	Progress Progress       `json:"progress"`
+	Goal     Goal           `json:"goal"`
+	Daily    *DailyProgress `json:"daily,omitempty"` // The day's derived value after this change
 }
 
-func (p *Progress) CalculateCompletionRate(target float64) {
-	if target <= 0 {
-		p.CompletionRate = 0
+func (p *Progress) CalculateCompletionRate(goal *Goal) {
+	if goal.IsChecklist() && goal.ChecklistItems != nil {
+		p.CompletionRate = goal.ChecklistCompletion(p.TrackedDate, p.CheckedItems())
 		return
 	}
-	p.CompletionRate = (p.Value / target) * 100
-	if p.CompletionRate > 100 {
-		p.CompletionRate = 100
-	}
+	p.CompletionRate = goal.CompletionRateFor(p.Value)
 }
 
 func (p *Progress) GetIntensityLevel() int {
-	switch {
-	case p.CompletionRate >= 90:
-		return 4 // Highest intensity
-	case p.CompletionRate >= 70:
-		return 3
-	case p.CompletionRate >= 40:
-		return 2
-	case p.CompletionRate >= 10:
-		return 1
-	default:
-		return 0 // No progress
-	}
+	return IntensityLevel(p.CompletionRate)
 }
 
+// IntensityLevel maps a completion rate to a heatmap intensity bucket of the
+// default palette. Goals with their own palette use HeatmapPalette instead.
+func IntensityLevel(completionRate float64) int {
+	return defaultPalette.Level(completionRate)
+}
+
+var defaultPalette = palette.Resolve()
+
 func (req *CreateProgressTimeRequest) ConvertToMinutes() float64 {
 	return float64(req.Hours*60 + req.Minutes)
 }
 
+// Localize fills in the display fields for the user's preferred unit system
+func (p *Progress) Localize(goal *Goal, system units.System) {
+	p.ValueDisplay = goal.FormatValueIn(p.Value, system)
+	goal.Localize(system)
+}
+
+// FormatValueByGoalType formats the value for a built-in goal type without loading the goal
 func (p *Progress) FormatValueByGoalType(goalType GoalType, unit string) string {
-	switch goalType {
-	case GoalTypeTime:
-		hours := int(p.Value) / 60
-		minutes := int(p.Value) % 60
-		if hours > 0 && minutes > 0 {
-			return fmt.Sprintf("%dh %dm", hours, minutes)
-		} else if hours > 0 {
-			return fmt.Sprintf("%dh", hours)
-		}
-		return fmt.Sprintf("%dm", minutes)
-	case GoalTypeBoolean:
-		if p.Value > 0 {
-			return "Completed"
-		}
-		return "Not completed"
-	default:
-		return fmt.Sprintf("%.1f %s", p.Value, unit)
-	}
+	goal := Goal{Type: goalType, Unit: unit}
+	return goal.FormatValueDisplay(p.Value)
 }
 
 func (p *Progress) IsConsideredComplete() bool {
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	DefaultScaleMin = 1
+	DefaultScaleMax = 5
+)
+
+// RatingScale describes the scale a rating goal is scored on, e.g. 1-5 with
+// optional labels ("awful" ... "great") for each level
+type RatingScale struct {
+	Min    int      `json:"min"`
+	Max    int      `json:"max"`
+	Labels []string `json:"labels,omitempty"` // One per level, lowest first
+}
+
+func (g *Goal) RatingScale() RatingScale {
+	return RatingScale{Min: g.ScaleMin, Max: g.ScaleMax, Labels: g.ScaleLabels}
+}
+
+// ValidateRatingScale fills in the default 1-5 scale and checks the labels line up with the levels
+func (g *Goal) ValidateRatingScale() error {
+	if g.Type != GoalTypeRating {
+		return nil
+	}
+	if g.ScaleMin == 0 && g.ScaleMax == 0 {
+		g.ScaleMin, g.ScaleMax = DefaultScaleMin, DefaultScaleMax
+	}
+	if g.ScaleMin >= g.ScaleMax {
+		return fmt.Errorf("rating scale max must be greater than min")
+	}
+	if g.ScaleMax-g.ScaleMin > 100 {
+		return fmt.Errorf("rating scale can have at most 101 levels")
+	}
+	if len(g.ScaleLabels) > 0 && len(g.ScaleLabels) != g.ScaleMax-g.ScaleMin+1 {
+		return fmt.Errorf("rating scale needs one label per level (%d)", g.ScaleMax-g.ScaleMin+1)
+	}
+	return nil
+}
+
+// Validate checks that a value is a whole level on the scale
+func (s RatingScale) Validate(value float64) error {
+	if value != math.Trunc(value) {
+		return fmt.Errorf("rating must be a whole number")
+	}
+	if value < float64(s.Min) || value > float64(s.Max) {
+		return fmt.Errorf("rating must be between %d and %d", s.Min, s.Max)
+	}
+	return nil
+}
+
+// CompletionRate places a rating on the scale: the lowest level is 0%, the highest 100%
+func (s RatingScale) CompletionRate(value float64) float64 {
+	if s.Max <= s.Min {
+		return 0
+	}
+	rate := (value - float64(s.Min)) / float64(s.Max-s.Min) * 100
+	return math.Max(0, math.Min(rate, 100))
+}
+
+// Label returns the label for a level, or "" when the scale has none
+func (s RatingScale) Label(value float64) string {
+	index := int(math.Round(value)) - s.Min
+	if index < 0 || index >= len(s.Labels) {
+		return ""
+	}
+	return s.Labels[index]
+}
+
+// Format renders a rating as "4/5" or "4/5 (Good)" when the level has a label
+func (s RatingScale) Format(value float64) string {
+	if s.Max <= s.Min {
+		// Scale unknown, only the level itself can be shown
+		return fmt.Sprintf("%.0f", value)
+	}
+	display := fmt.Sprintf("%.0f/%d", value, s.Max)
+	if label := s.Label(value); label != "" {
+		display += fmt.Sprintf(" (%s)", label)
+	}
+	return display
+}
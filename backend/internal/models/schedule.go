@@ -0,0 +1,190 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ScheduleType string
+
+const (
+	ScheduleTypeDaily        ScheduleType = "daily"          // Every day is a scheduled day
+	ScheduleTypeWeekdays     ScheduleType = "weekdays"       // Specific weekdays (e.g., "gym Mon/Wed/Fri")
+	ScheduleTypeInterval     ScheduleType = "interval"       // Every N days, counted from the schedule anchor
+	ScheduleTypeTimesPerWeek ScheduleType = "times_per_week" // X completions per week, on any days
+)
+
+func (st ScheduleType) IsValid() bool {
+	switch st {
+	case ScheduleTypeDaily, ScheduleTypeWeekdays, ScheduleTypeInterval, ScheduleTypeTimesPerWeek:
+		return true
+	}
+	return false
+}
+
+// WeekdaySet is a bitmask of weekdays, bit 0 being Sunday (matching time.Weekday).
+// It is stored as an integer and encoded in JSON as a list of short day names.
+type WeekdaySet uint8
+
+var weekdayNames = [7]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func NewWeekdaySet(days ...time.Weekday) WeekdaySet {
+	var s WeekdaySet
+	for _, d := range days {
+		s |= 1 << uint(d)
+	}
+	return s
+}
+
+func (s WeekdaySet) Contains(day time.Weekday) bool {
+	return s&(1<<uint(day)) != 0
+}
+
+func (s WeekdaySet) IsEmpty() bool {
+	return s&0x7f == 0
+}
+
+func (s WeekdaySet) MarshalJSON() ([]byte, error) {
+	days := []string{}
+	for i, name := range weekdayNames {
+		if s.Contains(time.Weekday(i)) {
+			days = append(days, name)
+		}
+	}
+	return json.Marshal(days)
+}
+
+func (s *WeekdaySet) UnmarshalJSON(data []byte) error {
+	var days []string
+	if err := json.Unmarshal(data, &days); err != nil {
+		return fmt.Errorf("weekdays must be a list of day names: %w", err)
+	}
+
+	var set WeekdaySet
+	for _, day := range days {
+		found := false
+		for i, name := range weekdayNames {
+			if strings.HasPrefix(strings.ToLower(day), name) {
+				set |= 1 << uint(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid weekday %q", day)
+		}
+	}
+	*s = set
+	return nil
+}
+
+// ScheduleRequest carries the schedule fields shared by goal create and update requests
+type ScheduleRequest struct {
+	ScheduleType         *ScheduleType `json:"schedule_type,omitempty"`
+	ScheduleWeekdays     *WeekdaySet   `json:"schedule_weekdays,omitempty"`
+	ScheduleInterval     *int          `json:"schedule_interval,omitempty"`
+	ScheduleTimesPerWeek *int          `json:"schedule_times_per_week,omitempty"`
+}
+
+// ApplySchedule copies the provided schedule fields onto the goal and validates the result
+func (g *Goal) ApplySchedule(req ScheduleRequest) error {
+	if req.ScheduleType != nil {
+		g.ScheduleType = *req.ScheduleType
+	}
+	if req.ScheduleWeekdays != nil {
+		g.ScheduleWeekdays = *req.ScheduleWeekdays
+	}
+	if req.ScheduleInterval != nil {
+		g.ScheduleInterval = *req.ScheduleInterval
+	}
+	if req.ScheduleTimesPerWeek != nil {
+		g.ScheduleTimesPerWeek = *req.ScheduleTimesPerWeek
+	}
+	if g.ScheduleType == "" {
+		g.ScheduleType = ScheduleTypeDaily
+	}
+	if err := g.ValidateSchedule(); err != nil {
+		return err
+	}
+	if g.IsChallenge() && g.StartDate != nil {
+		return g.validateChallengeSchedule()
+	}
+	return nil
+}
+
+func (g *Goal) ValidateSchedule() error {
+	if !g.ScheduleType.IsValid() {
+		return fmt.Errorf("invalid schedule type %q", g.ScheduleType)
+	}
+
+	switch g.ScheduleType {
+	case ScheduleTypeWeekdays:
+		if g.ScheduleWeekdays.IsEmpty() {
+			return fmt.Errorf("weekday schedules must include at least one day")
+		}
+	case ScheduleTypeInterval:
+		if g.ScheduleInterval < 1 || g.ScheduleInterval > 365 {
+			return fmt.Errorf("schedule interval must be between 1 and 365 days")
+		}
+	case ScheduleTypeTimesPerWeek:
+		if g.ScheduleTimesPerWeek < 1 || g.ScheduleTimesPerWeek > 7 {
+			return fmt.Errorf("times per week must be between 1 and 7")
+		}
+	}
+	return nil
+}
+
+// ScheduleAnchor is the first day interval schedules count from
+func (g *Goal) ScheduleAnchor() time.Time {
+	if g.StartDate != nil {
+		return dateOnly(*g.StartDate)
+	}
+	return dateOnly(g.CreatedAt)
+}
+
+// IsScheduledOn reports whether the goal expects progress on the given day.
+// Unscheduled days are neutral: they neither extend nor break a streak.
+// Times-per-week goals accept progress on any day, so every day is scheduled
+// and the weekly quota is enforced by WeeklyQuota instead.
+func (g *Goal) IsScheduledOn(date time.Time) bool {
+	switch g.ScheduleType {
+	case ScheduleTypeWeekdays:
+		return g.ScheduleWeekdays.Contains(date.Weekday())
+	case ScheduleTypeInterval:
+		if g.ScheduleInterval <= 1 {
+			return true
+		}
+		days := daysBetween(g.ScheduleAnchor(), dateOnly(date))
+		if days < 0 {
+			return false
+		}
+		return days%g.ScheduleInterval == 0
+	default:
+		return true
+	}
+}
+
+// WeeklyQuota returns the number of completions required per week, or 0 when
+// the goal is judged day by day
+func (g *Goal) WeeklyQuota() int {
+	if g.ScheduleType == ScheduleTypeTimesPerWeek {
+		return g.ScheduleTimesPerWeek
+	}
+	return 0
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// LocalDate is the calendar date of t in loc, as midnight UTC like stored dates
+func LocalDate(t time.Time, loc *time.Location) time.Time {
+	return dateOnly(t.In(loc))
+}
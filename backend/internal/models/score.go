@@ -0,0 +1,146 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const MaxGoalWeight = 10
+
+type ScoreRollup string
+
+const (
+	ScoreRollupDay   ScoreRollup = "day"
+	ScoreRollupWeek  ScoreRollup = "week" // Weeks start on Monday
+	ScoreRollupMonth ScoreRollup = "month"
+)
+
+func (r ScoreRollup) IsValid() bool {
+	switch r {
+	case ScoreRollupDay, ScoreRollupWeek, ScoreRollupMonth:
+		return true
+	}
+	return false
+}
+
+// ScorePoint is the user's productivity score for a day, week or month: the
+// weighted mean completion of the goals scheduled in it
+type ScorePoint struct {
+	Date           time.Time `json:"date"`     // First day of the period
+	EndDate        time.Time `json:"end_date"` // Last day of the period
+	Score          float64   `json:"score"`    // 0-100
+	IntensityLevel int       `json:"intensity_level"`
+	Color          string    `json:"color,omitempty"` // Resolved from the user's palette
+	GoalDays       int       `json:"goal_days"`       // Scheduled goal-days counted
+	TotalWeight    float64   `json:"total_weight"`    // Sum of their weights
+}
+
+func (g *Goal) ValidateWeight() error {
+	if g.Weight < 0 || g.Weight > MaxGoalWeight {
+		return errors.New("weight must be between 0 and 10")
+	}
+	return nil
+}
+
+// DailyScores scores every day from start to end. rates holds each goal's
+// derived completion per tracked day ("2006-01-02"). A goal counts on the days
+// it is scheduled within its lifetime, and inactive goals and weekly quotas
+// only where they have progress; excused days and zero-weight goals are left
+// out. Days with nothing to score are omitted.
+func DailyScores(goals []Goal, rates map[uuid.UUID]map[string]float64, excuses map[uuid.UUID]Excuses, start, end time.Time) []ScorePoint {
+	points := []ScorePoint{}
+	last := dateOnly(end)
+	for day := dateOnly(start); !day.After(last); day = day.AddDate(0, 0, 1) {
+		key := DateKey(day)
+		point := ScorePoint{Date: day, EndDate: day}
+		var weighted float64
+		for i := range goals {
+			goal := &goals[i]
+			if goal.Weight <= 0 || !goal.IsScheduledOn(day) {
+				continue
+			}
+			rate, logged := rates[goal.ID][key]
+			if !logged {
+				if !goal.IsActive || !goal.isLiveOn(day) || goal.WeeklyQuota() > 0 {
+					continue
+				}
+				if _, excused := excuses[goal.ID][key]; excused {
+					continue
+				}
+				rate = goal.MissingEntryCompletion()
+			}
+			weighted += goal.Weight * rate
+			point.TotalWeight += goal.Weight
+			point.GoalDays++
+		}
+		if point.GoalDays == 0 {
+			continue
+		}
+		point.Score = weighted / point.TotalWeight
+		point.IntensityLevel = IntensityLevel(point.Score)
+		points = append(points, point)
+	}
+	return points
+}
+
+// isLiveOn reports whether the day falls between the goal's creation, or its
+// start date, and its end date
+func (g *Goal) isLiveOn(day time.Time) bool {
+	if day.Before(dateOnly(g.CreatedAt)) {
+		return false
+	}
+	if g.StartDate != nil && day.Before(dateOnly(*g.StartDate)) {
+		return false
+	}
+	return g.EndDate == nil || !day.After(dateOnly(*g.EndDate))
+}
+
+// RollupScores combines daily scores into weeks or months, weighting each day
+// by the goal weight behind it
+func RollupScores(days []ScorePoint, rollup ScoreRollup) []ScorePoint {
+	if rollup == ScoreRollupDay || rollup == "" {
+		return days
+	}
+
+	points := []ScorePoint{}
+	var weighted float64
+	for _, day := range days {
+		start, end := scorePeriod(day.Date, rollup)
+		if len(points) == 0 || !points[len(points)-1].Date.Equal(start) {
+			if len(points) > 0 {
+				finishScorePoint(&points[len(points)-1], weighted)
+			}
+			points = append(points, ScorePoint{Date: start, EndDate: end})
+			weighted = 0
+		}
+		point := &points[len(points)-1]
+		weighted += day.Score * day.TotalWeight
+		point.TotalWeight += day.TotalWeight
+		point.GoalDays += day.GoalDays
+	}
+	if len(points) > 0 {
+		finishScorePoint(&points[len(points)-1], weighted)
+	}
+	return points
+}
+
+func finishScorePoint(point *ScorePoint, weighted float64) {
+	if point.TotalWeight > 0 {
+		point.Score = weighted / point.TotalWeight
+	}
+	point.IntensityLevel = IntensityLevel(point.Score)
+}
+
+// scorePeriod returns the first and last day of the week or month holding date
+func scorePeriod(date time.Time, rollup ScoreRollup) (time.Time, time.Time) {
+	day := dateOnly(date)
+	if rollup == ScoreRollupMonth {
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+	offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+	start := day.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, 6)
+}
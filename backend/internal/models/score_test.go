@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDailyScoresSkipsUnloggedQuotaDays(t *testing.T) {
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	daily := Goal{ID: uuid.New(), Type: GoalTypeBoolean, Target: 1, ScheduleType: ScheduleTypeDaily, IsActive: true, Weight: 1, CreatedAt: created}
+	quota := Goal{ID: uuid.New(), Type: GoalTypeBoolean, Target: 1, ScheduleType: ScheduleTypeTimesPerWeek, ScheduleTimesPerWeek: 3,
+		IsActive: true, Weight: 1, CreatedAt: created}
+
+	rates := map[uuid.UUID]map[string]float64{
+		daily.ID: {"2026-03-02": 100, "2026-03-03": 100},
+		quota.ID: {"2026-03-03": 50},
+	}
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	points := DailyScores([]Goal{daily, quota}, rates, nil, day, day.AddDate(0, 0, 1))
+
+	want := []struct {
+		score    float64
+		goalDays int
+	}{{100, 1}, {75, 2}}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i, point := range points {
+		if point.Score != want[i].score || point.GoalDays != want[i].goalDays {
+			t.Errorf("%s: score %v over %d goals, want %v over %d",
+				DateKey(point.Date), point.Score, point.GoalDays, want[i].score, want[i].goalDays)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareScope is what a public share link shows
+type ShareScope string
+
+const (
+	ShareScopeGoal     ShareScope = "goal"     // One goal's own heatmap
+	ShareScopeGroup    ShareScope = "group"    // The weighted score of a group's goals
+	ShareScopeCombined ShareScope = "combined" // The weighted score of every goal
+)
+
+func (s ShareScope) IsValid() bool {
+	switch s {
+	case ShareScopeGoal, ShareScopeGroup, ShareScopeCombined:
+		return true
+	}
+	return false
+}
+
+// ShareLink publishes a heatmap at /public/h/{token} without signing in. The
+// token is random, so it reveals nothing about the owner, and deleting the
+// link revokes it.
+type ShareLink struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	Token         string     `json:"token" gorm:"not null;size:64;uniqueIndex"`
+	Scope         ShareScope `json:"scope" gorm:"not null;size:10"`
+	GoalID        *uuid.UUID `json:"goal_id,omitempty" gorm:"type:uuid;index"`
+	GroupID       *uuid.UUID `json:"group_id,omitempty" gorm:"type:uuid;index"`
+	Title         string     `json:"title" gorm:"size:255"` // Shown instead of the goal or group name when set
+	HideNotes     bool       `json:"hide_notes" gorm:"not null;default:false"`
+	HideValues    bool       `json:"hide_values" gorm:"not null;default:false"`    // Completion stays, amounts don't
+	IntensityOnly bool       `json:"intensity_only" gorm:"not null;default:false"` // Just the colored days
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	User  User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Goal  *Goal      `json:"-" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+	Group *GoalGroup `json:"-" gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+}
+
+// Path is where the link is served, without a format extension
+func (s *ShareLink) Path() string {
+	return "/public/h/" + s.Token
+}
+
+// ShowsValues reports whether amounts may be published
+func (s *ShareLink) ShowsValues() bool {
+	return !s.HideValues && !s.IntensityOnly
+}
+
+// ShowsNotes reports whether entry notes may be published
+func (s *ShareLink) ShowsNotes() bool {
+	return !s.HideNotes && !s.IntensityOnly
+}
+
+type CreateShareLinkRequest struct {
+	Scope         ShareScope `json:"scope" validate:"required,oneof=goal group combined"`
+	GoalID        *uuid.UUID `json:"goal_id,omitempty"`
+	GroupID       *uuid.UUID `json:"group_id,omitempty"`
+	Title         string     `json:"title" validate:"max=255"`
+	HideNotes     bool       `json:"hide_notes"`
+	HideValues    bool       `json:"hide_values"`
+	IntensityOnly bool       `json:"intensity_only"`
+}
+
+func (r *CreateShareLinkRequest) Validate() error {
+	if !r.Scope.IsValid() {
+		return errors.New("scope must be goal, group or combined")
+	}
+	if (r.Scope == ShareScopeGoal) != (r.GoalID != nil) {
+		return errors.New("goal_id is required for, and only allowed with, the goal scope")
+	}
+	if (r.Scope == ShareScopeGroup) != (r.GroupID != nil) {
+		return errors.New("group_id is required for, and only allowed with, the group scope")
+	}
+	if len(r.Title) > 255 {
+		return errors.New("title must be at most 255 characters")
+	}
+	return nil
+}
+
+// UpdateShareLinkRequest changes what a link shows; its scope and token are fixed
+type UpdateShareLinkRequest struct {
+	Title         *string `json:"title,omitempty" validate:"omitempty,max=255"`
+	HideNotes     *bool   `json:"hide_notes,omitempty"`
+	HideValues    *bool   `json:"hide_values,omitempty"`
+	IntensityOnly *bool   `json:"intensity_only,omitempty"`
+}
+
+// ShareLinkResponse is a link as its owner sees it
+type ShareLinkResponse struct {
+	ShareLink
+	URL string `json:"url"` // Path of the JSON view; add .svg or .png for images
+}
+
+// PublicHeatmap is the JSON a share link serves. It carries no user or goal IDs.
+type PublicHeatmap struct {
+	Title         string             `json:"title"`
+	Scope         ShareScope         `json:"scope"`
+	StartDate     time.Time          `json:"start_date"`
+	EndDate       time.Time          `json:"end_date"`
+	CurrentStreak int                `json:"current_streak"`
+	Legend        []string           `json:"legend"` // Level colors, lowest first
+	Days          []PublicHeatmapDay `json:"days"`
+}
+
+// PublicHeatmapDay is one goal's day, or one score day for groups and
+// combined links, with what the link hides left empty
+type PublicHeatmapDay struct {
+	Date           time.Time  `json:"date"`
+	GoalTitle      string     `json:"goal_title,omitempty"`
+	IntensityLevel int        `json:"intensity_level"`
+	Color          string     `json:"color"`
+	Excused        ExcuseKind `json:"excused,omitempty"`
+	CompletionRate *float64   `json:"completion_rate,omitempty"` // Score for groups and combined links
+	FormattedValue string     `json:"formatted_value,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+}
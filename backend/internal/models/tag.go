@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a user's label for goals and individual progress entries (e.g., "deep-work").
+// Unlike groups, a goal can carry any number of tags.
+type Tag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_name"`
+	Name      string    `json:"name" gorm:"not null;size:50;uniqueIndex:idx_tags_user_name"`
+	ColorCode string    `json:"color_code" gorm:"size:7"` // Optional hex color
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type CreateTagRequest struct {
+	Name      string `json:"name" validate:"required,max=50"`
+	ColorCode string `json:"color_code" validate:"omitempty,hexcolor"`
+}
+
+type UpdateTagRequest struct {
+	Name      *string `json:"name,omitempty" validate:"omitempty,max=50"`
+	ColorCode *string `json:"color_code,omitempty" validate:"omitempty,hexcolor"`
+}
+
+// TagSummary totals the progress carrying a tag, either directly or through its goal
+type TagSummary struct {
+	TagID             uuid.UUID `json:"tag_id"`
+	Name              string    `json:"name"`
+	ColorCode         string    `json:"color_code"`
+	GoalCount         int       `json:"goal_count"`
+	EntryCount        int       `json:"entry_count"`
+	TrackedDays       int       `json:"tracked_days"`       // Distinct days with tagged progress
+	AverageCompletion float64   `json:"average_completion"` // Over derived goal days
+	TotalMinutes      float64   `json:"total_minutes"`      // From time goals
+}
+
+// TagIDs lists the tags on an entry together with those on its goal
+func (p *Progress) TagIDs(goal *Goal) map[uuid.UUID]bool {
+	ids := make(map[uuid.UUID]bool, len(p.Tags)+len(goal.Tags))
+	for _, tag := range p.Tags {
+		ids[tag.ID] = true
+	}
+	for _, tag := range goal.Tags {
+		ids[tag.ID] = true
+	}
+	return ids
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarikozturk017/streak-map/backend/internal/palette"
+)
+
+// TemplateGoal is a portable goal definition. Targets are in the template's own
+// unit (e.g., 5 with unit "km"), and nothing in it refers to a user's records,
+// so it can be shared as JSON and instantiated by anyone.
+type TemplateGoal struct {
+	Key               string                       `json:"key,omitempty"` // Set on built-in templates
+	Title             string                       `json:"title"`
+	Description       string                       `json:"description,omitempty"`
+	Type              GoalType                     `json:"type"`
+	ColorCode         string                       `json:"color_code"`
+	TrackingFrequency TrackingFrequency            `json:"tracking_frequency,omitempty"`
+	Target            float64                      `json:"target"`
+	Unit              string                       `json:"unit"`
+	Aggregation       Aggregation                  `json:"aggregation,omitempty"`
+	Direction         GoalDirection                `json:"direction,omitempty"`
+	TargetMax         *float64                     `json:"target_max,omitempty"`
+	Mode              GoalMode                     `json:"mode,omitempty"`
+	ChallengeDays     int                          `json:"challenge_days,omitempty"`
+	ChallengePassDays int                          `json:"challenge_pass_days,omitempty"`
+	ScaleMin          int                          `json:"scale_min,omitempty"`
+	ScaleMax          int                          `json:"scale_max,omitempty"`
+	ScaleLabels       []string                     `json:"scale_labels,omitempty"`
+	ChecklistRule     ChecklistRule                `json:"checklist_rule,omitempty"`
+	ChecklistItems    []CreateChecklistItemRequest `json:"checklist_items,omitempty"`
+	Weight            *float64                     `json:"weight,omitempty"` // Score priority, 1 when unset
+	Palette           *palette.Palette             `json:"palette,omitempty"`
+	Metric            *TemplateMetric              `json:"metric,omitempty"` // Definition for custom goals
+	ScheduleRequest
+}
+
+// TemplateMetric carries a custom metric type along with a template. It is
+// matched by name against the user's metric types, or created.
+type TemplateMetric struct {
+	Name           string      `json:"name"`
+	UnitLabel      string      `json:"unit_label"`
+	Precision      int         `json:"precision,omitempty"`
+	MinValue       *float64    `json:"min_value,omitempty"`
+	MaxValue       *float64    `json:"max_value,omitempty"`
+	Aggregation    Aggregation `json:"aggregation,omitempty"`
+	FormatTemplate string      `json:"format_template,omitempty"`
+}
+
+// TemplatePack is a set of templates instantiated together into one goal group
+type TemplatePack struct {
+	Key         string         `json:"key"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	ColorCode   string         `json:"color_code"`
+	Goals       []TemplateGoal `json:"goals"`
+}
+
+// GoalTemplate is a template a user saved, from one of their goals or from shared JSON
+type GoalTemplate struct {
+	ID          uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID    `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name        string       `json:"name" gorm:"not null;size:255"`
+	Description string       `json:"description" gorm:"type:text"`
+	Definition  TemplateGoal `json:"definition" gorm:"type:text;serializer:json"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TemplateDocument is the shareable JSON form of a template
+type TemplateDocument struct {
+	Name        string       `json:"name" validate:"required,max=255"`
+	Description string       `json:"description" validate:"max=1000"`
+	Definition  TemplateGoal `json:"definition" validate:"required"`
+}
+
+type SaveGoalTemplateRequest struct {
+	Name        string `json:"name" validate:"max=255"` // Defaults to the goal's title
+	Description string `json:"description" validate:"max=1000"`
+}
+
+// CreateGoalFromTemplateRequest names exactly one source: a built-in template
+// key, a saved template, a pack, or an inline template. The overrides apply to
+// single templates only.
+type CreateGoalFromTemplateRequest struct {
+	TemplateKey string        `json:"template_key,omitempty"`
+	TemplateID  *uuid.UUID    `json:"template_id,omitempty"`
+	PackKey     string        `json:"pack_key,omitempty"`
+	Template    *TemplateGoal `json:"template,omitempty"`
+
+	Title     string     `json:"title,omitempty" validate:"omitempty,max=255"`
+	ColorCode string     `json:"color_code,omitempty" validate:"omitempty,hexcolor"`
+	Target    *float64   `json:"target,omitempty" validate:"omitempty,min=0"`
+	GroupID   *uuid.UUID `json:"group_id,omitempty"`
+}
+
+type GoalsFromTemplateResponse struct {
+	Group *GoalGroup `json:"group,omitempty"` // Created for packs
+	Goals []Goal     `json:"goals"`
+}
+
+type TemplateCatalog struct {
+	Builtins []TemplateGoal `json:"builtins"`
+	Packs    []TemplatePack `json:"packs"`
+	Saved    []GoalTemplate `json:"saved"`
+}
+
+func (t *TemplateGoal) Validate() error {
+	if strings.TrimSpace(t.Title) == "" {
+		return errors.New("template title is required")
+	}
+	if !t.Type.IsValid() {
+		return fmt.Errorf("invalid goal type %q", t.Type)
+	}
+	if t.Type == GoalTypeCustom && t.Metric == nil {
+		return errors.New("custom templates must include a metric definition")
+	}
+	return nil
+}
+
+// CreateRequest turns the template into a goal create request. Custom goals
+// still need their metric type resolved into MetricTypeID.
+func (t *TemplateGoal) CreateRequest() CreateGoalRequest {
+	frequency := t.TrackingFrequency
+	if frequency == "" {
+		frequency = TrackingFrequencyDaily
+	}
+	return CreateGoalRequest{
+		Title:             t.Title,
+		Description:       t.Description,
+		Type:              t.Type,
+		ColorCode:         t.ColorCode,
+		TrackingFrequency: frequency,
+		Target:            t.Target,
+		Unit:              t.Unit,
+		Aggregation:       t.Aggregation,
+		ScaleMin:          t.ScaleMin,
+		ScaleMax:          t.ScaleMax,
+		ScaleLabels:       t.ScaleLabels,
+		ChecklistRule:     t.ChecklistRule,
+		Direction:         t.Direction,
+		TargetMax:         t.TargetMax,
+		Mode:              t.Mode,
+		ChallengeDays:     t.ChallengeDays,
+		ChallengePassDays: t.ChallengePassDays,
+		ScheduleRequest:   t.ScheduleRequest,
+		ChecklistItems:    t.ChecklistItems,
+		Weight:            t.Weight,
+		Palette:           t.Palette,
+	}
+}
+
+// TemplateFromGoal captures a goal's definition without its dates, group or
+// history. Checklist items and the metric type must be loaded.
+func TemplateFromGoal(goal *Goal) TemplateGoal {
+	template := TemplateGoal{
+		Title:             goal.Title,
+		Description:       goal.Description,
+		Type:              goal.Type,
+		ColorCode:         goal.ColorCode,
+		TrackingFrequency: goal.TrackingFrequency,
+		Target:            roundTemplateValue(goal.FromBaseUnit(goal.Target)),
+		Unit:              goal.Unit,
+		Aggregation:       goal.Aggregation,
+		Direction:         goal.Direction,
+		Mode:              goal.Mode,
+		ChallengeDays:     goal.ChallengeDays,
+		ChallengePassDays: goal.ChallengePassDays,
+		ScaleMin:          goal.ScaleMin,
+		ScaleMax:          goal.ScaleMax,
+		ScaleLabels:       goal.ScaleLabels,
+		ChecklistRule:     goal.ChecklistRule,
+	}
+	if goal.TargetMax != nil {
+		targetMax := roundTemplateValue(goal.FromBaseUnit(*goal.TargetMax))
+		template.TargetMax = &targetMax
+	}
+	if goal.Palette != nil {
+		goalPalette := *goal.Palette
+		template.Palette = &goalPalette
+	}
+	if goal.Weight != 1 {
+		weight := goal.Weight
+		template.Weight = &weight
+	}
+
+	scheduleType, weekdays := goal.ScheduleType, goal.ScheduleWeekdays
+	interval, timesPerWeek := goal.ScheduleInterval, goal.ScheduleTimesPerWeek
+	template.ScheduleRequest = ScheduleRequest{
+		ScheduleType:         &scheduleType,
+		ScheduleWeekdays:     &weekdays,
+		ScheduleInterval:     &interval,
+		ScheduleTimesPerWeek: &timesPerWeek,
+	}
+
+	now := time.Now()
+	for i := range goal.ChecklistItems {
+		item := &goal.ChecklistItems[i]
+		if goal.ItemCountsOn(item, now) {
+			template.ChecklistItems = append(template.ChecklistItems, CreateChecklistItemRequest{
+				Title:    item.Title,
+				Required: item.Required,
+			})
+		}
+	}
+
+	if goal.MetricType != nil {
+		template.Metric = &TemplateMetric{
+			Name:           goal.MetricType.Name,
+			UnitLabel:      goal.MetricType.UnitLabel,
+			Precision:      goal.MetricType.Precision,
+			MinValue:       goal.MetricType.MinValue,
+			MaxValue:       goal.MetricType.MaxValue,
+			Aggregation:    goal.MetricType.Aggregate,
+			FormatTemplate: goal.MetricType.FormatTemplate,
+		}
+	}
+	return template
+}
+
+// roundTemplateValue hides floating point noise from unit conversions (e.g., 4.999999 km)
+func roundTemplateValue(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
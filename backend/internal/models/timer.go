@@ -0,0 +1,126 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TimerState string
+
+const (
+	TimerStateRunning TimerState = "running"
+	TimerStatePaused  TimerState = "paused"
+	TimerStateStopped TimerState = "stopped"
+)
+
+// TimerSession is a server-side stopwatch for a time goal. A user has at most
+// one session that hasn't been stopped; the partial unique index enforces it.
+type TimerSession struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_timer_sessions_active_user,where:stopped_at IS NULL"`
+	GoalID    uuid.UUID  `json:"goal_id" gorm:"type:uuid;not null;index"`
+	State     TimerState `json:"state" gorm:"not null;size:10"`
+	StartedAt time.Time  `json:"started_at" gorm:"not null"`
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+	Notes     string     `json:"notes" gorm:"type:text"` // Copied onto the progress entries recorded at stop
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	Segments []TimerSegment `json:"segments" gorm:"foreignKey:SessionID"`
+
+	// Relationships
+	Goal Goal `json:"-" gorm:"foreignKey:GoalID;constraint:OnDelete:CASCADE"`
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TimerSegment is one uninterrupted run of a timer, between a start or resume
+// and the following pause or stop
+type TimerSegment struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SessionID uuid.UUID  `json:"session_id" gorm:"type:uuid;not null;index"`
+	StartedAt time.Time  `json:"started_at" gorm:"not null"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // Nil while running
+
+	Session TimerSession `json:"-" gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+}
+
+type StartTimerRequest struct {
+	Notes string `json:"notes" validate:"max=1000"`
+}
+
+type StopTimerRequest struct {
+	Notes *string `json:"notes,omitempty" validate:"omitempty,max=1000"`
+}
+
+type TimerStatus struct {
+	Session        TimerSession `json:"session"`
+	Goal           Goal         `json:"goal"`
+	ElapsedSeconds int64        `json:"elapsed_seconds"`    // Running time so far, paused stretches excluded
+	Progress       []Progress   `json:"progress,omitempty"` // Entries recorded when the timer stopped
+}
+
+// TimerDay is the running time that fell on one of the user's local days
+type TimerDay struct {
+	Date     time.Time // Local calendar day, as a UTC midnight like TrackedDate
+	Minutes  float64
+	LastSeen time.Time // End of the last segment part on this day
+}
+
+// Elapsed is the total running time up to now
+func (s *TimerSession) Elapsed(now time.Time) time.Duration {
+	var total time.Duration
+	for _, segment := range s.Segments {
+		total += segment.end(now).Sub(segment.StartedAt)
+	}
+	return total
+}
+
+// OpenSegment returns the segment that is still running, if any
+func (s *TimerSession) OpenSegment() *TimerSegment {
+	for i := range s.Segments {
+		if s.Segments[i].EndedAt == nil {
+			return &s.Segments[i]
+		}
+	}
+	return nil
+}
+
+// SplitByDay divides the running time across the calendar days of loc, so a
+// session that crosses local midnight credits each day with its own share
+func (s *TimerSession) SplitByDay(loc *time.Location, now time.Time) []TimerDay {
+	var days []TimerDay
+	byKey := make(map[string]int)
+
+	for _, segment := range s.Segments {
+		from, to := segment.StartedAt.In(loc), segment.end(now).In(loc)
+		for from.Before(to) {
+			y, m, d := from.Date()
+			midnight := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+			partEnd := to
+			if midnight.Before(to) {
+				partEnd = midnight
+			}
+
+			date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+			key := DateKey(date)
+			i, ok := byKey[key]
+			if !ok {
+				i = len(days)
+				byKey[key] = i
+				days = append(days, TimerDay{Date: date})
+			}
+			days[i].Minutes += partEnd.Sub(from).Minutes()
+			days[i].LastSeen = partEnd
+			from = partEnd
+		}
+	}
+	return days
+}
+
+func (seg *TimerSegment) end(now time.Time) time.Time {
+	if seg.EndedAt != nil {
+		return *seg.EndedAt
+	}
+	return now
+}
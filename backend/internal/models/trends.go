@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Moving average windows, in days
+const (
+	ShortTrendWindow = 7
+	LongTrendWindow  = 30
+)
+
+// TrendsResponse holds each goal's moving averages over a range, and optionally
+// how two periods compare
+type TrendsResponse struct {
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date"`
+	Goals     []GoalTrend `json:"goals"`
+}
+
+// GoalTrend is one goal's daily series with its 7- and 30-day moving averages.
+// Values are in the goal's display unit.
+type GoalTrend struct {
+	GoalID         uuid.UUID         `json:"goal_id"`
+	GoalTitle      string            `json:"goal_title"`
+	Unit           string            `json:"unit"`
+	Series         []TrendPoint      `json:"series"`
+	WeekOverWeek   TrendDelta        `json:"week_over_week"`   // Last 7 days against the 7 before
+	MonthOverMonth TrendDelta        `json:"month_over_month"` // Last 30 days against the 30 before
+	Comparison     *PeriodComparison `json:"comparison,omitempty"`
+}
+
+// TrendPoint is a goal's day with the moving averages ending on it. Averages
+// count every day the goal existed: a missed scheduled day has its missing-entry
+// completion, and adds 0 to summed values. Unscheduled days without entries are
+// left out of the completion averages, and days without entries are left out of
+// the value averages of goals that don't sum their entries (e.g. body weight).
+// Averages are nil when their window holds no such day.
+type TrendPoint struct {
+	Date                time.Time `json:"date"`
+	Logged              bool      `json:"logged"`
+	Value               *float64  `json:"value"`           // The day's aggregated value
+	CompletionRate      *float64  `json:"completion_rate"` // Nil on an unscheduled day without entries
+	CompletionAverage7  *float64  `json:"completion_avg_7"`
+	CompletionAverage30 *float64  `json:"completion_avg_30"`
+	ValueAverage7       *float64  `json:"value_avg_7"`
+	ValueAverage30      *float64  `json:"value_avg_30"`
+}
+
+// TrendDelta compares the moving averages at the end of the range with the
+// ones a window earlier. Changes are nil when either side is.
+type TrendDelta struct {
+	Completion         *float64 `json:"completion"`
+	PreviousCompletion *float64 `json:"previous_completion"`
+	CompletionChange   *float64 `json:"completion_change"` // In percentage points
+	Value              *float64 `json:"value"`
+	PreviousValue      *float64 `json:"previous_value"`
+	ValueChange        *float64 `json:"value_change"`
+}
+
+// TrendPeriod is a date range to compare, both ends inclusive
+type TrendPeriod struct {
+	Start time.Time `json:"start_date"`
+	End   time.Time `json:"end_date"`
+}
+
+// PeriodStats averages a goal over one period, counting days like the moving averages
+type PeriodStats struct {
+	TrendPeriod
+	LoggedDays        int      `json:"logged_days"`
+	AverageCompletion *float64 `json:"average_completion"`
+	AverageValue      *float64 `json:"average_value"`
+	TotalValue        float64  `json:"total_value"`
+}
+
+// PeriodComparison sets period B against period A
+type PeriodComparison struct {
+	A                PeriodStats `json:"a"`
+	B                PeriodStats `json:"b"`
+	CompletionChange *float64    `json:"completion_change"` // B minus A, in percentage points
+	ValueChange      *float64    `json:"value_change"`      // B minus A
+}
@@ -5,21 +5,26 @@ import (
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"github.com/tarikozturk017/streak-map/backend/internal/palette"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
 )
 
 type User struct {
-	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Email           string     `json:"email" gorm:"uniqueIndex;not null"`
-	Username        string     `json:"username" gorm:"uniqueIndex;not null"`
-	PasswordHash    string     `json:"-" gorm:"not null"`
-	FirstName       string     `json:"first_name"`
-	LastName        string     `json:"last_name"`
-	ProfileImageURL string     `json:"profile_image_url"`
-	IsActive        bool       `json:"is_active" gorm:"default:true"`
-	IsVerified      bool       `json:"is_verified" gorm:"default:false"`
-	LastLoginAt     *time.Time `json:"last_login_at"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID              uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email           string           `json:"email" gorm:"uniqueIndex;not null"`
+	Username        string           `json:"username" gorm:"uniqueIndex;not null"`
+	PasswordHash    string           `json:"-" gorm:"not null"`
+	FirstName       string           `json:"first_name"`
+	LastName        string           `json:"last_name"`
+	ProfileImageURL string           `json:"profile_image_url"`
+	IsActive        bool             `json:"is_active" gorm:"default:true"`
+	IsVerified      bool             `json:"is_verified" gorm:"default:false"`
+	UnitSystem      units.System     `json:"unit_system" gorm:"not null;default:'metric'"`               // Preferred system for rendering values
+	Timezone        string           `json:"timezone" gorm:"not null;default:'UTC';size:64"`             // IANA name, decides where the user's days start
+	HeatmapPalette  *palette.Palette `json:"heatmap_palette,omitempty" gorm:"type:text;serializer:json"` // Default for goals without their own
+	LastLoginAt     *time.Time       `json:"last_login_at"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
 }
 
 type CreateUserRequest struct {
@@ -30,6 +35,14 @@ type CreateUserRequest struct {
 	LastName  string `json:"last_name" validate:"required"`
 }
 
+type UpdateUserRequest struct {
+	FirstName      *string          `json:"first_name,omitempty"`
+	LastName       *string          `json:"last_name,omitempty"`
+	UnitSystem     *units.System    `json:"unit_system,omitempty" validate:"omitempty,oneof=metric imperial"`
+	Timezone       *string          `json:"timezone,omitempty" validate:"omitempty,max=64"`
+	HeatmapPalette *palette.Palette `json:"heatmap_palette,omitempty"` // An empty palette restores the defaults
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
@@ -51,6 +64,18 @@ func (u *User) SetPassword(password string) error {
 	return nil
 }
 
+// Location returns the user's time zone, falling back to UTC when it's unset or unknown
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
 	return err == nil
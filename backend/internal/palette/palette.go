@@ -0,0 +1,185 @@
+// Package palette turns completion rates into heatmap intensity levels and
+// colors. A palette has N levels above level 0 (no meaningful progress), each
+// starting at a completion threshold, and N+1 colors: either explicit stops or
+// a gradient from a start to an end color.
+package palette
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const MaxLevels = 10
+
+// Palette is stored on goals and users. Unset fields fall through to the next
+// layer when resolved, so a goal can override just its end color.
+type Palette struct {
+	Thresholds []float64 `json:"thresholds,omitempty"`  // Lowest completion rate of levels 1..N, ascending
+	Levels     int       `json:"levels,omitempty"`      // Evenly split levels, when no thresholds are given
+	StartColor string    `json:"start_color,omitempty"` // Level 0 of the gradient
+	EndColor   string    `json:"end_color,omitempty"`   // Level N of the gradient
+	Stops      []string  `json:"stops,omitempty"`       // Explicit color per level 0..N, overrides the gradient
+}
+
+// Default reproduces the original heatmap buckets: 10/40/70/90, from white to red
+func Default() Palette {
+	return Palette{
+		Thresholds: []float64{10, 40, 70, 90},
+		StartColor: "#FFFFFF",
+		EndColor:   "#E53935",
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+func (p *Palette) IsEmpty() bool {
+	return p == nil || (len(p.Thresholds) == 0 && p.Levels == 0 && p.StartColor == "" && p.EndColor == "" && len(p.Stops) == 0)
+}
+
+// Validate checks a palette as given, before it is merged with other layers
+func (p *Palette) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if len(p.Thresholds) > 0 && p.Levels > 0 && p.Levels != len(p.Thresholds) {
+		return errors.New("levels must match the number of thresholds")
+	}
+	if len(p.Thresholds) > MaxLevels || p.Levels < 0 || p.Levels > MaxLevels {
+		return fmt.Errorf("a palette can have at most %d levels", MaxLevels)
+	}
+	for i, threshold := range p.Thresholds {
+		if threshold <= 0 || threshold > 100 {
+			return errors.New("thresholds must be above 0 and at most 100")
+		}
+		if i > 0 && threshold <= p.Thresholds[i-1] {
+			return errors.New("thresholds must be in ascending order")
+		}
+	}
+	for _, color := range append([]string{p.StartColor, p.EndColor}, p.Stops...) {
+		if color != "" && !hexColor.MatchString(color) {
+			return fmt.Errorf("invalid color %q, expected #RRGGBB", color)
+		}
+	}
+	if len(p.Stops) > 0 {
+		levels := p.Levels
+		if len(p.Thresholds) > 0 {
+			levels = len(p.Thresholds)
+		}
+		if levels > 0 && len(p.Stops) != levels+1 {
+			return fmt.Errorf("expected %d color stops, one per level including 0", levels+1)
+		}
+		if len(p.Stops) < 2 || len(p.Stops) > MaxLevels+1 {
+			return fmt.Errorf("expected between 2 and %d color stops", MaxLevels+1)
+		}
+	}
+	return nil
+}
+
+// Resolve merges palettes, earlier ones taking precedence, on top of Default.
+// The level structure (thresholds or level count) comes from a single layer,
+// and explicit stops are only used when they fit it.
+func Resolve(layers ...*Palette) Palette {
+	resolved := Palette{}
+	for _, layer := range append(layers, defaultPalette()) {
+		if layer == nil {
+			continue
+		}
+		if len(resolved.Thresholds) == 0 && resolved.Levels == 0 {
+			resolved.Thresholds = layer.Thresholds
+			resolved.Levels = layer.Levels
+		}
+		if resolved.StartColor == "" {
+			resolved.StartColor = layer.StartColor
+		}
+		if resolved.EndColor == "" {
+			resolved.EndColor = layer.EndColor
+		}
+		if len(resolved.Stops) == 0 {
+			resolved.Stops = layer.Stops
+		}
+	}
+
+	if len(resolved.Thresholds) == 0 {
+		resolved.Thresholds = evenThresholds(resolved.Levels)
+	}
+	resolved.Levels = len(resolved.Thresholds)
+	if len(resolved.Stops) != resolved.Levels+1 {
+		resolved.Stops = nil
+	}
+	return resolved
+}
+
+func defaultPalette() *Palette {
+	p := Default()
+	return &p
+}
+
+// evenThresholds splits 0-100 into n levels, the first starting at any progress
+func evenThresholds(n int) []float64 {
+	thresholds := make([]float64, n)
+	for i := range thresholds {
+		thresholds[i] = math.Max(1, math.Round(float64(i)*100/float64(n)))
+	}
+	return thresholds
+}
+
+// Level returns the intensity level, 0 to Levels, for a completion rate.
+// Call it on a resolved palette.
+func (p Palette) Level(completionRate float64) int {
+	level := 0
+	for _, threshold := range p.Thresholds {
+		if completionRate >= threshold {
+			level++
+		}
+	}
+	return level
+}
+
+// Color returns the color of a level on a resolved palette
+func (p Palette) Color(level int) string {
+	levels := len(p.Thresholds)
+	if level < 0 {
+		level = 0
+	}
+	if level > levels {
+		level = levels
+	}
+	if len(p.Stops) == levels+1 {
+		return strings.ToUpper(p.Stops[level])
+	}
+	if levels == 0 {
+		return strings.ToUpper(p.EndColor)
+	}
+	return interpolate(p.StartColor, p.EndColor, float64(level)/float64(levels))
+}
+
+// Colors lists the colors of every level of a resolved palette, 0 first
+func (p Palette) Colors() []string {
+	colors := make([]string, len(p.Thresholds)+1)
+	for level := range colors {
+		colors[level] = p.Color(level)
+	}
+	return colors
+}
+
+// interpolate blends two hex colors in RGB, t running from 0 (from) to 1 (to)
+func interpolate(from, to string, t float64) string {
+	r1, g1, b1 := parseHex(from)
+	r2, g2, b2 := parseHex(to)
+	blend := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return fmt.Sprintf("#%02X%02X%02X", blend(r1, r2), blend(g1, g2), blend(b1, b2))
+}
+
+func parseHex(color string) (uint8, uint8, uint8) {
+	if !hexColor.MatchString(color) {
+		return 0, 0, 0
+	}
+	value, _ := strconv.ParseUint(color[1:], 16, 32)
+	return uint8(value >> 16), uint8(value >> 8), uint8(value)
+}
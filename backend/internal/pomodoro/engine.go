@@ -0,0 +1,354 @@
+// Package pomodoro runs focus/break cycles on the server. Sessions live in
+// memory until their long break ends; completed and interrupted focus
+// intervals are handed to a Recorder.
+package pomodoro
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Phase string
+
+const (
+	PhaseFocus      Phase = "focus"
+	PhaseShortBreak Phase = "short_break"
+	PhaseLongBreak  Phase = "long_break"
+)
+
+type EventType string
+
+const (
+	EventPhaseStarted   EventType = "phase_started"
+	EventPhaseCompleted EventType = "phase_completed"
+	EventInterrupted    EventType = "interrupted" // A focus interval ended early
+	EventStopped        EventType = "stopped"
+	EventFinished       EventType = "finished" // The long break ended, and with it the session
+)
+
+var (
+	ErrAlreadyRunning = errors.New("a pomodoro is already running")
+	ErrNotRunning     = errors.New("no pomodoro is running")
+)
+
+// Config sets the phase lengths. A long break follows Cycles focus intervals
+// and ends the session.
+type Config struct {
+	Focus      time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+	Cycles     int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Focus:      25 * time.Minute,
+		ShortBreak: 5 * time.Minute,
+		LongBreak:  15 * time.Minute,
+		Cycles:     4,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Focus < time.Minute || c.Focus > 4*time.Hour {
+		return fmt.Errorf("focus length must be between 1 and 240 minutes")
+	}
+	if c.ShortBreak < time.Minute || c.ShortBreak > time.Hour {
+		return fmt.Errorf("short break length must be between 1 and 60 minutes")
+	}
+	if c.LongBreak < time.Minute || c.LongBreak > 2*time.Hour {
+		return fmt.Errorf("long break length must be between 1 and 120 minutes")
+	}
+	if c.Cycles < 1 || c.Cycles > 12 {
+		return fmt.Errorf("cycles must be between 1 and 12")
+	}
+	return nil
+}
+
+func (c Config) length(phase Phase) time.Duration {
+	switch phase {
+	case PhaseShortBreak:
+		return c.ShortBreak
+	case PhaseLongBreak:
+		return c.LongBreak
+	default:
+		return c.Focus
+	}
+}
+
+// Interval is a focus interval that ended, either by running its full length
+// or by being cut short
+type Interval struct {
+	UserID    uuid.UUID
+	GoalID    uuid.UUID
+	StartedAt time.Time
+	EndedAt   time.Time
+	Planned   time.Duration
+	Completed bool
+}
+
+// Recorder persists finished focus intervals
+type Recorder interface {
+	RecordInterval(interval Interval) error
+}
+
+type Event struct {
+	Type   EventType `json:"type"`
+	Phase  Phase     `json:"phase"`
+	Cycle  int       `json:"cycle"` // Focus intervals completed in the current set
+	EndsAt time.Time `json:"ends_at,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Snapshot is the observable state of a user's pomodoro
+type Snapshot struct {
+	GoalID            uuid.UUID `json:"goal_id"`
+	Phase             Phase     `json:"phase"`
+	Cycle             int       `json:"cycle"`
+	Cycles            int       `json:"cycles"`
+	PhaseStartedAt    time.Time `json:"phase_started_at"`
+	PhaseEndsAt       time.Time `json:"phase_ends_at"`
+	RemainingSeconds  int64     `json:"remaining_seconds"`
+	FocusMinutes      float64   `json:"focus_minutes"`
+	ShortBreakMinutes float64   `json:"short_break_minutes"`
+	LongBreakMinutes  float64   `json:"long_break_minutes"`
+}
+
+type session struct {
+	userID       uuid.UUID
+	goalID       uuid.UUID
+	config       Config
+	phase        Phase
+	cycle        int
+	phaseStarted time.Time
+	phaseEnds    time.Time
+	timer        *time.Timer
+	seq          int // Counts phases, so a timer that fired late can tell it is stale
+}
+
+type Engine struct {
+	mu          sync.Mutex
+	sessions    map[uuid.UUID]*session
+	subscribers map[uuid.UUID]map[chan Event]struct{}
+	recorder    Recorder
+	now         func() time.Time
+}
+
+func NewEngine(recorder Recorder) *Engine {
+	return &Engine{
+		sessions:    make(map[uuid.UUID]*session),
+		subscribers: make(map[uuid.UUID]map[chan Event]struct{}),
+		recorder:    recorder,
+		now:         time.Now,
+	}
+}
+
+// Start begins a focus interval credited to goalID
+func (e *Engine) Start(userID, goalID uuid.UUID, config Config) (Snapshot, error) {
+	if err := config.Validate(); err != nil {
+		return Snapshot{}, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, running := e.sessions[userID]; running {
+		return Snapshot{}, ErrAlreadyRunning
+	}
+
+	s := &session{userID: userID, goalID: goalID, config: config}
+	e.sessions[userID] = s
+	e.enterPhase(s, PhaseFocus, e.now())
+	return s.snapshot(e.now()), nil
+}
+
+// Skip ends the current phase early and moves on. Skipping focus records an
+// interrupted interval. Skipping the long break finishes the session, and
+// running is false.
+func (e *Engine) Skip(userID uuid.UUID) (snapshot Snapshot, running bool, err error) {
+	e.mu.Lock()
+	s, running := e.sessions[userID]
+	if !running {
+		e.mu.Unlock()
+		return Snapshot{}, false, ErrNotRunning
+	}
+	now := e.now()
+	s.timer.Stop()
+	interval := e.endPhase(s, now, false)
+	_, running = e.sessions[userID]
+	if running {
+		snapshot = s.snapshot(now)
+	}
+	e.mu.Unlock()
+
+	e.record(interval)
+	return snapshot, running, nil
+}
+
+// Stop ends the pomodoro. A focus interval in progress counts as interrupted.
+func (e *Engine) Stop(userID uuid.UUID) error {
+	e.mu.Lock()
+	s, running := e.sessions[userID]
+	if !running {
+		e.mu.Unlock()
+		return ErrNotRunning
+	}
+	now := e.now()
+	s.timer.Stop()
+	delete(e.sessions, userID)
+
+	var interval *Interval
+	if s.phase == PhaseFocus {
+		interval = s.interval(now, false)
+		e.publish(userID, Event{Type: EventInterrupted, Phase: s.phase, Cycle: s.cycle, At: now})
+	}
+	e.publish(userID, Event{Type: EventStopped, Phase: s.phase, Cycle: s.cycle, At: now})
+	e.mu.Unlock()
+
+	e.record(interval)
+	return nil
+}
+
+func (e *Engine) Current(userID uuid.UUID) (Snapshot, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s, running := e.sessions[userID]
+	if !running {
+		return Snapshot{}, false
+	}
+	return s.snapshot(e.now()), true
+}
+
+// Subscribe returns a stream of the user's phase events. The returned function
+// must be called to unsubscribe.
+func (e *Engine) Subscribe(userID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	e.mu.Lock()
+	if e.subscribers[userID] == nil {
+		e.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	e.subscribers[userID][ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.subscribers[userID][ch]; ok {
+			delete(e.subscribers[userID], ch)
+			close(ch)
+		}
+		if len(e.subscribers[userID]) == 0 {
+			delete(e.subscribers, userID)
+		}
+	}
+}
+
+// enterPhase starts a phase and schedules its completion. Callers hold e.mu.
+func (e *Engine) enterPhase(s *session, phase Phase, now time.Time) {
+	s.phase = phase
+	s.phaseStarted = now
+	s.phaseEnds = now.Add(s.config.length(phase))
+	s.seq++
+	seq := s.seq
+	s.timer = time.AfterFunc(s.phaseEnds.Sub(now), func() { e.complete(s, seq) })
+	e.publish(s.userID, Event{Type: EventPhaseStarted, Phase: phase, Cycle: s.cycle, EndsAt: s.phaseEnds, At: now})
+}
+
+// complete runs when the timer of phase seq fires
+func (e *Engine) complete(s *session, seq int) {
+	e.mu.Lock()
+	if e.sessions[s.userID] != s || s.seq != seq {
+		e.mu.Unlock()
+		return // Stopped, skipped or replaced in the meantime
+	}
+	interval := e.endPhase(s, e.now(), true)
+	e.mu.Unlock()
+
+	e.record(interval)
+}
+
+// endPhase closes the current phase and enters the next one, or finishes the
+// session after its long break. Callers hold e.mu.
+func (e *Engine) endPhase(s *session, now time.Time, completed bool) *Interval {
+	var interval *Interval
+	next := PhaseFocus
+
+	if s.phase == PhaseFocus {
+		interval = s.interval(now, completed)
+		if completed {
+			s.cycle++
+			e.publish(s.userID, Event{Type: EventPhaseCompleted, Phase: s.phase, Cycle: s.cycle, At: now})
+		} else {
+			e.publish(s.userID, Event{Type: EventInterrupted, Phase: s.phase, Cycle: s.cycle, At: now})
+		}
+		next = PhaseShortBreak
+		if s.cycle >= s.config.Cycles {
+			next = PhaseLongBreak
+		}
+	} else {
+		e.publish(s.userID, Event{Type: EventPhaseCompleted, Phase: s.phase, Cycle: s.cycle, At: now})
+		if s.phase == PhaseLongBreak {
+			delete(e.sessions, s.userID)
+			e.publish(s.userID, Event{Type: EventFinished, Phase: s.phase, Cycle: s.cycle, At: now})
+			return nil
+		}
+	}
+
+	e.enterPhase(s, next, now)
+	return interval
+}
+
+// publish fans an event out without blocking; slow subscribers miss events.
+// Callers hold e.mu.
+func (e *Engine) publish(userID uuid.UUID, event Event) {
+	for ch := range e.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (e *Engine) record(interval *Interval) {
+	if interval == nil || e.recorder == nil {
+		return
+	}
+	if err := e.recorder.RecordInterval(*interval); err != nil {
+		log.Printf("Failed to record pomodoro interval for goal %s: %v", interval.GoalID, err)
+	}
+}
+
+func (s *session) interval(now time.Time, completed bool) *Interval {
+	return &Interval{
+		UserID:    s.userID,
+		GoalID:    s.goalID,
+		StartedAt: s.phaseStarted,
+		EndedAt:   now,
+		Planned:   s.config.Focus,
+		Completed: completed,
+	}
+}
+
+func (s *session) snapshot(now time.Time) Snapshot {
+	remaining := s.phaseEnds.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Snapshot{
+		GoalID:            s.goalID,
+		Phase:             s.phase,
+		Cycle:             s.cycle,
+		Cycles:            s.config.Cycles,
+		PhaseStartedAt:    s.phaseStarted,
+		PhaseEndsAt:       s.phaseEnds,
+		RemainingSeconds:  int64(remaining.Seconds()),
+		FocusMinutes:      s.config.Focus.Minutes(),
+		ShortBreakMinutes: s.config.ShortBreak.Minutes(),
+		LongBreakMinutes:  s.config.LongBreak.Minutes(),
+	}
+}
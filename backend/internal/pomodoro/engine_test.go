@@ -0,0 +1,237 @@
+package pomodoro
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type recorder struct {
+	intervals []Interval
+}
+
+func (r *recorder) RecordInterval(interval Interval) error {
+	r.intervals = append(r.intervals, interval)
+	return nil
+}
+
+// testEngine returns an engine whose clock only moves when the test says so
+func testEngine(t *testing.T) (*Engine, *recorder, *time.Time) {
+	t.Helper()
+	rec := &recorder{}
+	e := NewEngine(rec)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+	t.Cleanup(func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		for _, s := range e.sessions {
+			s.timer.Stop()
+		}
+	})
+	return e, rec, &now
+}
+
+// finishPhase moves the clock to the end of the current phase and fires its
+// timer, as if it had run out
+func finishPhase(t *testing.T, e *Engine, now *time.Time, userID uuid.UUID) {
+	t.Helper()
+	e.mu.Lock()
+	s, running := e.sessions[userID]
+	if !running {
+		e.mu.Unlock()
+		t.Fatal("no session to finish a phase of")
+	}
+	s.timer.Stop()
+	seq := s.seq
+	*now = s.phaseEnds
+	e.mu.Unlock()
+
+	e.complete(s, seq)
+}
+
+func testConfig() Config {
+	return Config{Focus: 25 * time.Minute, ShortBreak: 5 * time.Minute, LongBreak: 15 * time.Minute, Cycles: 2}
+}
+
+func TestPhaseOrder(t *testing.T) {
+	e, rec, now := testEngine(t)
+	userID, goalID := uuid.New(), uuid.New()
+	events, unsubscribe := e.Subscribe(userID)
+	defer unsubscribe()
+
+	start := *now
+	if _, err := e.Start(userID, goalID, testConfig()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	var phases []Phase
+	for range 4 {
+		snapshot, running := e.Current(userID)
+		if !running {
+			t.Fatal("session ended early")
+		}
+		phases = append(phases, snapshot.Phase)
+		finishPhase(t, e, now, userID)
+	}
+
+	want := []Phase{PhaseFocus, PhaseShortBreak, PhaseFocus, PhaseLongBreak}
+	if !reflect.DeepEqual(phases, want) {
+		t.Errorf("phases = %v, want %v", phases, want)
+	}
+	if len(rec.intervals) != 2 {
+		t.Fatalf("recorded %d intervals, want 2", len(rec.intervals))
+	}
+	for i, interval := range rec.intervals {
+		if !interval.Completed || interval.GoalID != goalID || interval.EndedAt.Sub(interval.StartedAt) != 25*time.Minute {
+			t.Errorf("interval %d = %+v, want a completed 25 minute interval", i, interval)
+		}
+	}
+	if got := rec.intervals[1].StartedAt; !got.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("second interval started at %s, want after the short break", got)
+	}
+
+	var types []EventType
+	for len(events) > 0 {
+		types = append(types, (<-events).Type)
+	}
+	wantTypes := []EventType{
+		EventPhaseStarted, EventPhaseCompleted, // Focus
+		EventPhaseStarted, EventPhaseCompleted, // Short break
+		EventPhaseStarted, EventPhaseCompleted, // Focus
+		EventPhaseStarted, EventPhaseCompleted, // Long break
+		EventFinished,
+	}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("events = %v, want %v", types, wantTypes)
+	}
+}
+
+func TestSessionEndsAfterLongBreak(t *testing.T) {
+	e, rec, now := testEngine(t)
+	userID := uuid.New()
+	if _, err := e.Start(userID, uuid.New(), testConfig()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	for range 4 {
+		finishPhase(t, e, now, userID)
+	}
+	if _, running := e.Current(userID); running {
+		t.Fatal("session still running after its long break")
+	}
+	if len(rec.intervals) != 2 {
+		t.Errorf("recorded %d intervals, want 2", len(rec.intervals))
+	}
+
+	// A new session can be started once the last one finished
+	if _, err := e.Start(userID, uuid.New(), testConfig()); err != nil {
+		t.Errorf("Start() after finishing = %v", err)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	e, rec, now := testEngine(t)
+	userID := uuid.New()
+	start := *now
+	if _, err := e.Start(userID, uuid.New(), testConfig()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	*now = start.Add(10 * time.Minute)
+	snapshot, running, err := e.Skip(userID)
+	if err != nil || !running {
+		t.Fatalf("Skip() = %v, %t", err, running)
+	}
+	if snapshot.Phase != PhaseShortBreak || snapshot.Cycle != 0 {
+		t.Errorf("after skipping focus: phase %s cycle %d, want short_break cycle 0", snapshot.Phase, snapshot.Cycle)
+	}
+	want := []Interval{{
+		UserID:    userID,
+		GoalID:    rec.intervals[0].GoalID,
+		StartedAt: start,
+		EndedAt:   start.Add(10 * time.Minute),
+		Planned:   25 * time.Minute,
+	}}
+	if !reflect.DeepEqual(rec.intervals, want) {
+		t.Errorf("intervals = %+v, want %+v", rec.intervals, want)
+	}
+
+	// Skipping a break records nothing
+	if _, _, err := e.Skip(userID); err != nil {
+		t.Fatalf("Skip() = %v", err)
+	}
+	if len(rec.intervals) != 1 {
+		t.Errorf("recorded %d intervals, want 1", len(rec.intervals))
+	}
+
+	// A skipped focus interval doesn't count towards the long break
+	finishPhase(t, e, now, userID)
+	finishPhase(t, e, now, userID)
+	if snapshot, _ := e.Current(userID); snapshot.Phase != PhaseFocus {
+		t.Errorf("phase = %s, want focus", snapshot.Phase)
+	}
+	finishPhase(t, e, now, userID)
+	if snapshot, _ := e.Current(userID); snapshot.Phase != PhaseLongBreak {
+		t.Fatalf("phase = %s, want long_break", snapshot.Phase)
+	}
+
+	if _, running, err := e.Skip(userID); err != nil || running {
+		t.Errorf("skipping the long break = %v, %t, want the session finished", err, running)
+	}
+	if _, _, err := e.Skip(userID); err != ErrNotRunning {
+		t.Errorf("Skip() after finishing = %v, want %v", err, ErrNotRunning)
+	}
+}
+
+func TestStop(t *testing.T) {
+	e, rec, now := testEngine(t)
+	userID := uuid.New()
+	start := *now
+	if _, err := e.Start(userID, uuid.New(), testConfig()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	*now = start.Add(7 * time.Minute)
+	if err := e.Stop(userID); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if len(rec.intervals) != 1 || rec.intervals[0].Completed || !rec.intervals[0].EndedAt.Equal(*now) {
+		t.Errorf("intervals = %+v, want one interrupted after 7 minutes", rec.intervals)
+	}
+	if _, running := e.Current(userID); running {
+		t.Error("session still running after Stop()")
+	}
+	if err := e.Stop(userID); err != ErrNotRunning {
+		t.Errorf("Stop() twice = %v, want %v", err, ErrNotRunning)
+	}
+}
+
+func TestStaleTimerIsIgnored(t *testing.T) {
+	e, rec, now := testEngine(t)
+	userID := uuid.New()
+	if _, err := e.Start(userID, uuid.New(), testConfig()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	e.mu.Lock()
+	s := e.sessions[userID]
+	seq := s.seq
+	e.mu.Unlock()
+
+	*now = now.Add(time.Minute)
+	if _, _, err := e.Skip(userID); err != nil {
+		t.Fatalf("Skip() = %v", err)
+	}
+	// The focus timer fires after all, having lost the race with Skip
+	e.complete(s, seq)
+
+	if snapshot, _ := e.Current(userID); snapshot.Phase != PhaseShortBreak {
+		t.Errorf("phase = %s, want short_break", snapshot.Phase)
+	}
+	if len(rec.intervals) != 1 {
+		t.Errorf("recorded %d intervals, want 1", len(rec.intervals))
+	}
+}
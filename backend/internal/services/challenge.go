@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+type ChallengeService struct {
+	db *gorm.DB
+}
+
+func NewChallengeService(db *gorm.DB) *ChallengeService {
+	return &ChallengeService{db: db}
+}
+
+// Status evaluates a challenge goal as of the owner's local date and, when its
+// last day is over, records the outcome and deactivates the goal
+func (s *ChallengeService) Status(goal *models.Goal, asOf time.Time) (*models.ChallengeStatus, error) {
+	if !goal.IsChallenge() || goal.StartDate == nil {
+		return nil, errors.New("goal is not a challenge")
+	}
+
+	rates, err := DailyCompletionRates(s.db, *goal, *goal.StartDate, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	successDays := make(map[string]bool)
+	for day := *goal.StartDate; !day.After(asOf); day = day.AddDate(0, 0, 1) {
+		if !goal.IsScheduledOn(day) {
+			continue
+		}
+		key := day.Format("2006-01-02")
+		rate, logged := rates[key]
+		if !logged {
+			rate = goal.MissingEntryCompletion()
+		}
+		if goal.IsSuccess(rate, logged) && (logged || key != asOf.Format("2006-01-02")) {
+			successDays[key] = true
+		}
+	}
+
+	status := goal.ChallengeStatus(successDays, asOf)
+
+	var result models.ChallengeResult
+	err = s.db.Where("goal_id = ?", goal.ID).First(&result).Error
+	switch {
+	case err == nil:
+		status.Result = &result
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	case status.State == models.ChallengeStatePassed || status.State == models.ChallengeStateFailed:
+		if status.Result, err = s.finalize(goal, status); err != nil {
+			return nil, err
+		}
+	}
+	return &status, nil
+}
+
+func (s *ChallengeService) finalize(goal *models.Goal, status models.ChallengeStatus) (*models.ChallengeResult, error) {
+	result := models.ChallengeResult{
+		ID:             uuid.New(),
+		GoalID:         goal.ID,
+		UserID:         goal.UserID,
+		Passed:         status.State == models.ChallengeStatePassed,
+		SuccessfulDays: status.SuccessfulDays,
+		RequiredDays:   status.RequiredDays,
+		TotalDays:      status.TotalDays,
+		StartDate:      status.StartDate,
+		EndDate:        status.EndDate,
+		CompletedAt:    time.Now(),
+		CreatedAt:      time.Now(),
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&result)
+		if created.Error != nil {
+			return created.Error
+		}
+		if created.RowsAffected == 0 {
+			// A concurrent request recorded the outcome first
+			return tx.Where("goal_id = ?", goal.ID).First(&result).Error
+		}
+		// History stays in place; the goal just stops asking for progress
+		return tx.Model(&models.Goal{}).Where("id = ?", goal.ID).
+			Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	goal.IsActive = false
+	return &result, nil
+}
+
+// FinalizeExpired records outcomes for every active challenge whose last day
+// has passed in its owner's time zone
+func (s *ChallengeService) FinalizeExpired(now time.Time) error {
+	// Owners ahead of UTC can be a day further along, so today in UTC is included
+	var goals []models.Goal
+	if err := s.db.Where("mode = ? AND is_active = ? AND end_date <= ?",
+		models.GoalModeChallenge, true, now.UTC().Format("2006-01-02")).
+		Preload("User").
+		Find(&goals).Error; err != nil {
+		return err
+	}
+
+	for i := range goals {
+		if _, err := s.Status(&goals[i], models.LocalDate(now, goals[i].User.Location())); err != nil {
+			log.Printf("Failed to finalize challenge %s: %v", goals[i].ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// CorrelationService computes goal correlations and keeps recent results.
+// Results are cached under a fingerprint of everything they are derived from,
+// so a changed entry, goal or excused day leads to a fresh computation and the
+// stale result simply ages out.
+type CorrelationService struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	size    int
+	results map[string]*models.CorrelationResponse
+	order   []string
+}
+
+func NewCorrelationService(db *gorm.DB, size int) *CorrelationService {
+	return &CorrelationService{
+		db:      db,
+		size:    size,
+		results: make(map[string]*models.CorrelationResponse),
+	}
+}
+
+// Correlations compares the daily completion of every pair of the filtered
+// goals, up to today in loc. The fingerprint it returns identifies the result
+// and changes whenever it would.
+func (s *CorrelationService) Correlations(filter models.HeatmapFilter, opts models.CorrelationOptions, loc *time.Location) (*models.CorrelationResponse, string, error) {
+	start, end := filter.StartDate, filter.EndDate
+	today := models.LocalDate(time.Now(), loc)
+	if end.After(today) {
+		end = today
+	}
+
+	goals, err := HeatmapGoals(s.db, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.SliceStable(goals, func(i, j int) bool {
+		if goals[i].SortOrder != goals[j].SortOrder {
+			return goals[i].SortOrder < goals[j].SortOrder
+		}
+		return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+	})
+	goalIDs := make([]uuid.UUID, 0, len(goals))
+	goalsByID := make(map[uuid.UUID]*models.Goal, len(goals))
+	for i := range goals {
+		goalIDs = append(goalIDs, goals[i].ID)
+		goalsByID[goals[i].ID] = &goals[i]
+	}
+
+	excuses, err := GoalExcuses(s.db, filter.UserID, goalIDs, start, end)
+	if err != nil {
+		return nil, "", err
+	}
+	fingerprint, err := s.fingerprint(filter.UserID, goals, excuses, opts, start, end, today)
+	if err != nil {
+		return nil, "", err
+	}
+	if result, ok := s.get(fingerprint); ok {
+		return result, fingerprint, nil
+	}
+
+	rates := make(map[uuid.UUID]map[string]float64, len(goals))
+	if len(goalIDs) > 0 {
+		var entries []models.Progress
+		if err := s.db.Where("user_id = ? AND goal_id IN ? AND tracked_date BETWEEN ? AND ?", filter.UserID, goalIDs, start, end).
+			Preload("Checks").
+			Order("tracked_date ASC, logged_at ASC").
+			Find(&entries).Error; err != nil {
+			return nil, "", err
+		}
+
+		entriesByGoal := make(map[uuid.UUID][]models.Progress)
+		for _, entry := range entries {
+			entriesByGoal[entry.GoalID] = append(entriesByGoal[entry.GoalID], entry)
+		}
+		for goalID, goalEntries := range entriesByGoal {
+			rates[goalID] = make(map[string]float64)
+			for _, day := range goalsByID[goalID].AggregateDaily(goalEntries) {
+				rates[goalID][models.DateKey(day.Date)] = day.CompletionRate
+			}
+		}
+	}
+
+	result := models.Correlate(goals, rates, excuses, opts, start, end, today)
+	s.put(fingerprint, result)
+	return result, fingerprint, nil
+}
+
+// fingerprint hashes the analysis inputs. today is part of them, as an empty
+// today only counts as missed once it is over.
+func (s *CorrelationService) fingerprint(userID uuid.UUID, goals []models.Goal, excuses map[uuid.UUID]models.Excuses, opts models.CorrelationOptions, start, end, today time.Time) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n%+v\n", userID, models.DateKey(start), models.DateKey(end), models.DateKey(today), opts)
+	if err := writeGoalsFingerprint(h, s.db, userID, goals, excuses, start, end); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+func (s *CorrelationService) get(fingerprint string) (*models.CorrelationResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.results[fingerprint]
+	return result, ok
+}
+
+// put keeps the result, dropping the oldest once full
+func (s *CorrelationService) put(fingerprint string, result *models.CorrelationResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.results[fingerprint]; ok {
+		return
+	}
+	for len(s.order) >= s.size && len(s.order) > 0 {
+		delete(s.results, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.results[fingerprint] = result
+	s.order = append(s.order, fingerprint)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// DailyProgress loads the goal's entries between start and end, inclusive, and
+// derives one value per tracked day using the goal's aggregation
+func DailyProgress(db *gorm.DB, goal models.Goal, start, end time.Time) ([]models.DailyProgress, error) {
+	if err := LoadChecklistItems(db, &goal); err != nil {
+		return nil, err
+	}
+
+	var entries []models.Progress
+	if err := db.Where("goal_id = ? AND DATE(tracked_date) BETWEEN ? AND ?",
+		goal.ID, start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Preload("Checks").
+		Order("tracked_date ASC, logged_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return goal.AggregateDaily(entries), nil
+}
+
+// LoadChecklistItems loads a checklist goal's items, archived ones included, so
+// completion can be derived from check-offs. Other goals are left untouched.
+func LoadChecklistItems(db *gorm.DB, goal *models.Goal) error {
+	if !goal.IsChecklist() || goal.ChecklistItems != nil {
+		return nil
+	}
+	items := []models.ChecklistItem{}
+	if err := db.Where("goal_id = ?", goal.ID).Order("position ASC, created_at ASC").Find(&items).Error; err != nil {
+		return err
+	}
+	goal.ChecklistItems = items
+	return nil
+}
+
+// DailyCompletionRates returns the goal's derived completion rate per tracked day
+// ("2006-01-02") between start and end, inclusive
+func DailyCompletionRates(db *gorm.DB, goal models.Goal, start, end time.Time) (map[string]float64, error) {
+	days, err := DailyProgress(db, goal, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]float64, len(days))
+	for _, day := range days {
+		rates[models.DateKey(day.Date)] = day.CompletionRate
+	}
+	return rates, nil
+}
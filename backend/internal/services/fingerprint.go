@@ -0,0 +1,58 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// writeGoalsFingerprint writes what the goals' derived days depend on: each
+// goal with its items, metric type and excused days, and their progress from
+// start to end, narrowed by any scopes, summarised by its count and latest
+// change. That moves on every create, update and delete in range, without
+// reading the entries themselves.
+func writeGoalsFingerprint(w io.Writer, db *gorm.DB, userID uuid.UUID, goals []models.Goal, excuses map[uuid.UUID]models.Excuses, start, end time.Time, scopes ...func(*gorm.DB) *gorm.DB) error {
+	goalIDs := make([]uuid.UUID, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+		fmt.Fprintf(w, "goal %s %d %t\n", goal.ID, goal.UpdatedAt.UnixNano(), goal.IsActive)
+		if goal.MetricType != nil {
+			fmt.Fprintf(w, "metric %s %d\n", goal.MetricType.ID, goal.MetricType.UpdatedAt.UnixNano())
+		}
+		for _, item := range goal.ChecklistItems {
+			fmt.Fprintf(w, "item %s %d\n", item.ID, item.UpdatedAt.UnixNano())
+		}
+
+		days := make([]string, 0, len(excuses[goal.ID]))
+		for key, kind := range excuses[goal.ID] {
+			days = append(days, key+" "+string(kind))
+		}
+		sort.Strings(days)
+		fmt.Fprintf(w, "excused %v\n", days)
+	}
+	if len(goalIDs) == 0 {
+		return nil
+	}
+
+	var stamp struct {
+		Count     int64
+		UpdatedAt *time.Time
+	}
+	if err := db.Model(&models.Progress{}).
+		Select("COUNT(*) AS count, MAX(updated_at) AS updated_at").
+		Where("user_id = ? AND goal_id IN ? AND tracked_date BETWEEN ? AND ?", userID, goalIDs, start, end).
+		Scopes(scopes...).
+		Scan(&stamp).Error; err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "progress %d", stamp.Count)
+	if stamp.UpdatedAt != nil {
+		fmt.Fprintf(w, " %d", stamp.UpdatedAt.UnixNano())
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+type FreezeService struct {
+	db *gorm.DB
+}
+
+func NewFreezeService(db *gorm.DB) *FreezeService {
+	return &FreezeService{db: db}
+}
+
+// Balance lists the user's freeze tokens, newest first
+func (s *FreezeService) Balance(userID uuid.UUID) (*models.FreezeBalance, error) {
+	tokens := []models.FreezeToken{}
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	balance := &models.FreezeBalance{MaxBanked: models.MaxBankedFreezes, Tokens: tokens}
+	for _, token := range tokens {
+		if token.IsUsed() {
+			balance.Used++
+		} else {
+			balance.Available++
+		}
+	}
+	return balance, nil
+}
+
+// sweepWindow is how many days of history Sweep loads at a time while finding
+// the streak that runs into its grace window
+const sweepWindow = 90
+
+// Sweep walks the goal's streak up to yesterday in the owner's time zone, so
+// the goal needs its User loaded. Every FreezeEarnEvery successful days earn a
+// token, and a missed scheduled day that would break a running streak spends
+// one. Only the last FreezeGraceDays days are acted on, so old history never
+// earns or spends tokens; before them it is read only as far back as the
+// running streak goes. Goals with a weekly quota are judged per week and are
+// left to excused periods.
+func (s *FreezeService) Sweep(goal *models.Goal, now time.Time) error {
+	if goal.WeeklyQuota() > 0 {
+		return nil
+	}
+
+	loc := goal.User.Location()
+	today := models.LocalDate(now, loc)
+	yesterday := today.AddDate(0, 0, -1)
+	graceStart := today.AddDate(0, 0, -models.FreezeGraceDays)
+
+	start := models.LocalDate(goal.CreatedAt, loc)
+	var firstEntry models.Progress
+	if err := s.db.Where("goal_id = ?", goal.ID).Order("tracked_date ASC").First(&firstEntry).Error; err == nil {
+		if firstEntry.TrackedDate.Before(start) {
+			start = models.LocalDate(firstEntry.TrackedDate, time.UTC)
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if start.After(yesterday) {
+		return nil
+	}
+	if graceStart.Before(start) {
+		graceStart = start
+	}
+
+	streak, err := s.streakBefore(goal, start, graceStart)
+	if err != nil {
+		return err
+	}
+	rates, err := DailyCompletionRates(s.db, *goal, graceStart, yesterday)
+	if err != nil {
+		return err
+	}
+	excuses, err := Excuses(s.db, goal, graceStart, yesterday)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var available []models.FreezeToken
+		if err := tx.Where("user_id = ? AND used_on IS NULL", goal.UserID).
+			Order("created_at ASC").
+			Find(&available).Error; err != nil {
+			return err
+		}
+
+		for day := graceStart; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+			success, counted := sweepDay(goal, day, rates, excuses)
+			if !counted {
+				continue
+			}
+
+			if success {
+				streak++
+				if streak%models.FreezeEarnEvery == 0 && len(available) < models.MaxBankedFreezes {
+					earnedOn := day
+					token := models.FreezeToken{
+						ID:           uuid.New(),
+						UserID:       goal.UserID,
+						Source:       models.FreezeSourceEarned,
+						EarnedGoalID: &goal.ID,
+						EarnedOn:     &earnedOn,
+						CreatedAt:    time.Now(),
+					}
+					result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&token)
+					if result.Error != nil {
+						return result.Error
+					}
+					if result.RowsAffected > 0 {
+						available = append(available, token)
+					}
+				}
+				continue
+			}
+
+			if streak > 0 && len(available) > 0 {
+				usedOn := day
+				result := tx.Model(&models.FreezeToken{}).
+					Where("id = ? AND used_on IS NULL", available[0].ID).
+					Updates(map[string]interface{}{"used_goal_id": goal.ID, "used_on": usedOn})
+				if result.Error != nil {
+					return result.Error
+				}
+				available = available[1:]
+				if result.RowsAffected > 0 {
+					continue
+				}
+			}
+			streak = 0
+		}
+		return nil
+	})
+}
+
+// streakBefore counts the successful days running up to day, walking back to
+// the first miss that wasn't excused or to the goal's first day. Days that
+// spent a token count as excused, so the streak carries across them.
+func (s *FreezeService) streakBefore(goal *models.Goal, first, day time.Time) (int, error) {
+	streak := 0
+	for end := day.AddDate(0, 0, -1); !end.Before(first); end = end.AddDate(0, 0, -sweepWindow) {
+		start := end.AddDate(0, 0, -(sweepWindow - 1))
+		if start.Before(first) {
+			start = first
+		}
+		rates, err := DailyCompletionRates(s.db, *goal, start, end)
+		if err != nil {
+			return 0, err
+		}
+		excuses, err := Excuses(s.db, goal, start, end)
+		if err != nil {
+			return 0, err
+		}
+
+		for d := end; !d.Before(start); d = d.AddDate(0, 0, -1) {
+			success, counted := sweepDay(goal, d, rates, excuses)
+			if !counted {
+				continue
+			}
+			if !success {
+				return streak, nil
+			}
+			streak++
+		}
+	}
+	return streak, nil
+}
+
+// sweepDay judges a day of the goal's streak. Unscheduled days and excused
+// misses don't count either way.
+func sweepDay(goal *models.Goal, day time.Time, rates map[string]float64, excuses models.Excuses) (success, counted bool) {
+	if !goal.IsScheduledOn(day) {
+		return false, false
+	}
+	key := day.Format("2006-01-02")
+	rate, logged := rates[key]
+	if !logged {
+		rate = goal.MissingEntryCompletion()
+	}
+	success = goal.IsSuccess(rate, logged)
+	if _, excused := excuses[key]; excused && !success {
+		return false, false
+	}
+	return success, true
+}
+
+// SweepAll runs Sweep for every active goal
+func (s *FreezeService) SweepAll(now time.Time) error {
+	var goals []models.Goal
+	if err := s.db.Where("is_active = ?", true).Preload("User").Preload("MetricType").Find(&goals).Error; err != nil {
+		return err
+	}
+
+	for i := range goals {
+		if err := s.Sweep(&goals[i], now); err != nil {
+			log.Printf("Failed to apply streak freezes for goal %s: %v", goals[i].ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *FreezeService) availableCount(userID uuid.UUID) (int, error) {
+	var count int64
+	if err := s.db.Model(&models.FreezeToken{}).
+		Where("user_id = ? AND used_on IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Excuses returns the goal's excused days between start and end, inclusive
+func Excuses(db *gorm.DB, goal *models.Goal, start, end time.Time) (models.Excuses, error) {
+	byGoal, err := GoalExcuses(db, goal.UserID, []uuid.UUID{goal.ID}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return byGoal[goal.ID], nil
+}
+
+// GoalExcuses returns the excused days of each of the user's goals between
+// start and end, inclusive. Spent freeze tokens and excused periods both count.
+func GoalExcuses(db *gorm.DB, userID uuid.UUID, goalIDs []uuid.UUID, start, end time.Time) (map[uuid.UUID]models.Excuses, error) {
+	excuses := make(map[uuid.UUID]models.Excuses, len(goalIDs))
+	for _, goalID := range goalIDs {
+		excuses[goalID] = models.Excuses{}
+	}
+	if len(goalIDs) == 0 {
+		return excuses, nil
+	}
+
+	from, to := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	var periods []models.ExcusedPeriod
+	if err := db.Where("user_id = ? AND start_date <= ? AND end_date >= ? AND (goal_id IS NULL OR goal_id IN ?)",
+		userID, to, from, goalIDs).
+		Find(&periods).Error; err != nil {
+		return nil, err
+	}
+	for _, period := range periods {
+		for day := period.StartDate; !day.After(period.EndDate); day = day.AddDate(0, 0, 1) {
+			key := day.Format("2006-01-02")
+			if key < from || key > to {
+				continue
+			}
+			for _, goalID := range goalIDs {
+				if period.Covers(goalID, day) {
+					excuses[goalID][key] = models.ExcuseVacation
+				}
+			}
+		}
+	}
+
+	var tokens []models.FreezeToken
+	if err := db.Where("user_id = ? AND used_goal_id IN ? AND used_on BETWEEN ? AND ?", userID, goalIDs, from, to).
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		key := token.UsedOn.Format("2006-01-02")
+		if _, ok := excuses[*token.UsedGoalID][key]; !ok {
+			excuses[*token.UsedGoalID][key] = models.ExcuseFreeze
+		}
+	}
+	return excuses, nil
+}
@@ -0,0 +1,422 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/heatmap"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/palette"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
+)
+
+// TaggedProgress limits a progress query to entries carrying one of the tags,
+// directly or through their goal
+func TaggedProgress(tagIDs []uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(tagIDs) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where("(goal_id IN (SELECT goal_id FROM goal_tags WHERE tag_id IN ?) OR id IN (SELECT progress_id FROM progress_tags WHERE tag_id IN ?))", tagIDs, tagIDs)
+	}
+}
+
+// HeatmapGoals loads the goals a heatmap filter covers, ready for deriving
+// daily values. The tag filter applies to the goals' own tags.
+func HeatmapGoals(db *gorm.DB, filter models.HeatmapFilter) ([]models.Goal, error) {
+	query := heatmapGoalQuery(db, filter)
+	if filter.ByTag {
+		query = query.Where("id IN (SELECT goal_id FROM goal_tags WHERE tag_id IN ?)", filter.TagIDs)
+	}
+
+	var goals []models.Goal
+	if err := query.Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+func heatmapGoalQuery(db *gorm.DB, filter models.HeatmapFilter) *gorm.DB {
+	query := db.Where("user_id = ?", filter.UserID)
+	if filter.GoalID != nil {
+		query = query.Where("id = ?", *filter.GoalID)
+	}
+	if filter.GroupID != nil {
+		query = query.Where("group_id = ?", *filter.GroupID)
+	}
+	return query.Preload("MetricType").
+		Preload("Tags").
+		Preload("ChecklistItems", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, created_at ASC") })
+}
+
+// HeatmapCells derives one cell per goal per day, from all of that day's
+// entries, with levels and colors resolved from each goal's palette. Frozen
+// and vacation days are marked, and get a cell of their own when nothing was
+// logged, so they read as excused rather than missed. Cells are sorted by date,
+// then by the goals' order.
+func HeatmapCells(db *gorm.DB, filter models.HeatmapFilter) ([]models.HeatmapData, error) {
+	var user models.User
+	if err := db.Select("unit_system", "heatmap_palette").First(&user, filter.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	var goals []models.Goal
+	if err := heatmapGoalQuery(db, filter).Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	heatmapData := []models.HeatmapData{}
+	if len(goals) == 0 {
+		return heatmapData, nil
+	}
+	goalIDs := make([]uuid.UUID, 0, len(goals))
+	goalsByID := make(map[uuid.UUID]*models.Goal, len(goals))
+	palettes := make(map[uuid.UUID]palette.Palette, len(goals))
+	for i := range goals {
+		goalIDs = append(goalIDs, goals[i].ID)
+		goalsByID[goals[i].ID] = &goals[i]
+		palettes[goals[i].ID] = goals[i].HeatmapPalette(user.HeatmapPalette)
+	}
+
+	query := db.Where("user_id = ? AND goal_id IN ? AND tracked_date BETWEEN ? AND ?",
+		filter.UserID, goalIDs, filter.StartDate, filter.EndDate)
+	if filter.ByTag {
+		query = query.Scopes(TaggedProgress(filter.TagIDs))
+	}
+	var entries []models.Progress
+	if err := query.
+		Preload("Checks").
+		Order("tracked_date ASC, logged_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	entriesByGoal := make(map[uuid.UUID][]models.Progress)
+	for _, entry := range entries {
+		entriesByGoal[entry.GoalID] = append(entriesByGoal[entry.GoalID], entry)
+	}
+
+	system := user.UnitSystem
+	if !system.IsValid() {
+		system = units.SystemMetric
+	}
+	for goalID, goalEntries := range entriesByGoal {
+		goal := goalsByID[goalID]
+		goalPalette := palettes[goal.ID]
+		for _, day := range goal.AggregateDaily(goalEntries) {
+			level := goalPalette.Level(day.CompletionRate)
+			heatmapData = append(heatmapData, models.HeatmapData{
+				Date:           day.Date,
+				GoalID:         goal.ID,
+				IsScheduled:    goal.IsScheduledOn(day.Date),
+				CompletionRate: day.CompletionRate,
+				IntensityLevel: level,
+				Color:          goalPalette.Color(level),
+				EntryCount:     day.EntryCount,
+				GoalDirection:  goal.Direction,
+				Value:          day.Value,
+				GoalTitle:      goal.Title,
+				GoalType:       goal.Type,
+				ColorCode:      goal.ColorCode,
+				Unit:           goal.Unit,
+				Notes:          day.Notes,
+				FormattedValue: goal.FormatValueIn(day.Value, system),
+			})
+		}
+	}
+
+	// Only active goals, and with a tag filter only the goals carrying a tag,
+	// get cells for excused days
+	var excusedGoalIDs []uuid.UUID
+	for _, goal := range goals {
+		if goal.IsActive && (!filter.ByTag || hasAnyTag(goal, filter.TagIDs)) {
+			excusedGoalIDs = append(excusedGoalIDs, goal.ID)
+		}
+	}
+	excuses, err := GoalExcuses(db, filter.UserID, excusedGoalIDs, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	for i := range heatmapData {
+		key := models.DateKey(heatmapData[i].Date)
+		if kind, ok := excuses[heatmapData[i].GoalID][key]; ok {
+			heatmapData[i].Excused = kind
+			delete(excuses[heatmapData[i].GoalID], key)
+		}
+	}
+	for goalID, days := range excuses {
+		goal := goalsByID[goalID]
+		for key, kind := range days {
+			if key < models.DateKey(goal.CreatedAt) {
+				continue
+			}
+			date, _ := time.Parse("2006-01-02", key)
+			heatmapData = append(heatmapData, models.HeatmapData{
+				Date:           date,
+				GoalID:         goal.ID,
+				IsScheduled:    goal.IsScheduledOn(date),
+				Excused:        kind,
+				Color:          palettes[goal.ID].Color(0),
+				GoalDirection:  goal.Direction,
+				GoalTitle:      goal.Title,
+				GoalType:       goal.Type,
+				ColorCode:      goal.ColorCode,
+				Unit:           goal.Unit,
+				FormattedValue: goal.FormatValueIn(0, system),
+			})
+		}
+	}
+
+	sort.Slice(heatmapData, func(i, j int) bool {
+		a, b := heatmapData[i], heatmapData[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return goalsByID[a.GoalID].SortOrder < goalsByID[b.GoalID].SortOrder ||
+			(goalsByID[a.GoalID].SortOrder == goalsByID[b.GoalID].SortOrder && a.GoalID.String() < b.GoalID.String())
+	})
+	return heatmapData, nil
+}
+
+// DenseHeatmap lists every date from the filter's start to its end, each with
+// the day's goal cells and their weighted score. The range holds dates in loc,
+// the user's time zone. Week columns start on weekStart.
+func DenseHeatmap(db *gorm.DB, filter models.HeatmapFilter, weekStart time.Weekday, loc *time.Location) (*models.DenseHeatmap, error) {
+	cells, err := HeatmapCells(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	points, err := ScoreSeries(db, filter, models.ScoreRollupDay)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := db.Select("heatmap_palette").First(&user, filter.UserID).Error; err != nil {
+		return nil, err
+	}
+	emptyColor := palette.Resolve(user.HeatmapPalette).Color(0)
+
+	start := time.Date(filter.StartDate.Year(), filter.StartDate.Month(), filter.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(filter.EndDate.Year(), filter.EndDate.Month(), filter.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	dense := &models.DenseHeatmap{
+		StartDate: start,
+		EndDate:   end,
+		Timezone:  loc.String(),
+		WeekStart: weekStart.String(),
+		Days:      []models.HeatmapDay{},
+	}
+	if end.Before(start) {
+		return dense, nil
+	}
+
+	cellsByDay := make(map[string][]models.HeatmapData)
+	for _, cell := range cells {
+		key := models.DateKey(cell.Date)
+		cellsByDay[key] = append(cellsByDay[key], cell)
+	}
+	pointsByDay := make(map[string]models.ScorePoint, len(points))
+	for _, point := range points {
+		pointsByDay[models.DateKey(point.Date)] = point
+	}
+
+	rowOf := func(day time.Time) int { return (int(day.Weekday()) - int(weekStart) + 7) % 7 }
+	firstColumn := start.AddDate(0, 0, -rowOf(start))
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := models.DateKey(day)
+		denseDay := models.HeatmapDay{
+			Date:      day,
+			DayOfWeek: int(day.Weekday()),
+			Row:       rowOf(day),
+			WeekIndex: int(day.Sub(firstColumn).Hours()/24) / 7,
+			Cells:     cellsByDay[key],
+			Aggregate: models.HeatmapAggregate{Color: emptyColor},
+		}
+		if denseDay.Cells == nil {
+			denseDay.Cells = []models.HeatmapData{}
+		}
+		if point, ok := pointsByDay[key]; ok {
+			denseDay.Aggregate.Score = point.Score
+			denseDay.Aggregate.IntensityLevel = point.IntensityLevel
+			denseDay.Aggregate.Color = point.Color
+			denseDay.Aggregate.ScheduledGoals = point.GoalDays
+		}
+		for _, cell := range denseDay.Cells {
+			if cell.EntryCount > 0 {
+				denseDay.Aggregate.LoggedGoals++
+			}
+			if cell.Excused != "" {
+				denseDay.Aggregate.ExcusedGoals++
+			}
+			denseDay.Aggregate.EntryCount += cell.EntryCount
+		}
+		dense.Days = append(dense.Days, denseDay)
+		dense.Weeks = denseDay.WeekIndex + 1
+	}
+	return dense, nil
+}
+
+// HeatmapCalendar builds a one-cell-per-day calendar for rendering: the goal's
+// own days when the filter names a goal, the weighted score of the filtered
+// goals otherwise
+func HeatmapCalendar(db *gorm.DB, filter models.HeatmapFilter) (heatmap.Calendar, error) {
+	calendar := heatmap.Calendar{
+		Start: filter.StartDate,
+		End:   filter.EndDate,
+		Days:  make(map[string]heatmap.Day),
+	}
+
+	var user models.User
+	if err := db.Select("heatmap_palette").First(&user, filter.UserID).Error; err != nil {
+		return calendar, err
+	}
+
+	if filter.GoalID != nil {
+		var goal models.Goal
+		if err := db.Where("id = ? AND user_id = ?", *filter.GoalID, filter.UserID).First(&goal).Error; err != nil {
+			return calendar, err
+		}
+		goalPalette := goal.HeatmapPalette(user.HeatmapPalette)
+		calendar.Title = goal.Title
+		calendar.EmptyColor = goalPalette.Color(0)
+		calendar.Legend = goalPalette.Colors()
+
+		cells, err := HeatmapCells(db, filter)
+		if err != nil {
+			return calendar, err
+		}
+		for _, cell := range cells {
+			title := fmt.Sprintf("%s: %s (%.0f%%)", cell.Date.Format("Jan 2, 2006"), cell.FormattedValue, cell.CompletionRate)
+			if cell.Excused != "" && cell.EntryCount == 0 {
+				title = fmt.Sprintf("%s: %s", cell.Date.Format("Jan 2, 2006"), cell.Excused)
+			}
+			calendar.Days[models.DateKey(cell.Date)] = heatmap.Day{
+				Date:    cell.Date,
+				Level:   cell.IntensityLevel,
+				Color:   cell.Color,
+				Title:   title,
+				Excused: cell.Excused != "",
+			}
+		}
+		return calendar, nil
+	}
+
+	scorePalette := palette.Resolve(user.HeatmapPalette)
+	calendar.Title = "Daily score"
+	calendar.EmptyColor = scorePalette.Color(0)
+	calendar.Legend = scorePalette.Colors()
+
+	points, err := ScoreSeries(db, filter, models.ScoreRollupDay)
+	if err != nil {
+		return calendar, err
+	}
+	for _, point := range points {
+		calendar.Days[models.DateKey(point.Date)] = heatmap.Day{
+			Date:  point.Date,
+			Level: point.IntensityLevel,
+			Color: point.Color,
+			Title: fmt.Sprintf("%s: score %.0f across %d goals", point.Date.Format("Jan 2, 2006"), point.Score, point.GoalDays),
+		}
+	}
+	return calendar, nil
+}
+
+// HeatmapFingerprint identifies the calendar HeatmapCalendar builds for the
+// filter on the given day, without building it: it covers the user's display
+// settings, the goals and their excused days, and a summary of their progress,
+// scoped the way the calendar reads them, so it changes whenever the calendar
+// could.
+func HeatmapFingerprint(db *gorm.DB, filter models.HeatmapFilter, today time.Time) (string, error) {
+	var user models.User
+	if err := db.Select("unit_system", "timezone", "heatmap_palette").First(&user, filter.UserID).Error; err != nil {
+		return "", err
+	}
+	userPalette, err := json.Marshal(user.HeatmapPalette)
+	if err != nil {
+		return "", err
+	}
+
+	// A goal's calendar holds its cells, which take the tag filter from the
+	// entries; otherwise it holds the score of the goals carrying the tags
+	cells := filter.GoalID != nil
+	var goals []models.Goal
+	if cells {
+		err = heatmapGoalQuery(db, filter).Find(&goals).Error
+	} else {
+		goals, err = HeatmapGoals(db, filter)
+	}
+	if err != nil {
+		return "", err
+	}
+	goalIDs := make([]uuid.UUID, 0, len(goals))
+	excusedGoalIDs := make([]uuid.UUID, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+		if !cells || (goal.IsActive && (!filter.ByTag || hasAnyTag(goal, filter.TagIDs))) {
+			excusedGoalIDs = append(excusedGoalIDs, goal.ID)
+		}
+	}
+	excuses, err := GoalExcuses(db, filter.UserID, excusedGoalIDs, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n", filter.UserID, models.DateKey(filter.StartDate), models.DateKey(filter.EndDate), models.DateKey(today))
+	fmt.Fprintf(h, "user %s %s %s\n", user.UnitSystem, user.Timezone, userPalette)
+	if filter.GoalID != nil {
+		fmt.Fprintf(h, "goal filter %s\n", *filter.GoalID)
+	}
+	if filter.GroupID != nil {
+		fmt.Fprintf(h, "group filter %s\n", *filter.GroupID)
+	}
+
+	var scopes []func(*gorm.DB) *gorm.DB
+	if filter.ByTag {
+		fmt.Fprintf(h, "tag filter %v\n", filter.TagIDs)
+	}
+	if filter.ByTag && cells {
+		scopes = append(scopes, TaggedProgress(filter.TagIDs))
+		for _, goal := range goals {
+			fmt.Fprintf(h, "goal %s tagged %t\n", goal.ID, hasAnyTag(goal, filter.TagIDs))
+		}
+		if len(goalIDs) > 0 {
+			// Tagging an entry doesn't touch the entry itself
+			var links struct {
+				Count  int64
+				Digest *string
+			}
+			if err := db.Raw(`SELECT COUNT(*) AS count, md5(string_agg(progress_id::text || tag_id::text, ',' ORDER BY progress_id, tag_id)) AS digest
+				FROM progress_tags
+				WHERE tag_id IN ? AND progress_id IN (SELECT id FROM progress WHERE user_id = ? AND goal_id IN ? AND tracked_date BETWEEN ? AND ?)`,
+				filter.TagIDs, filter.UserID, goalIDs, filter.StartDate, filter.EndDate).Scan(&links).Error; err != nil {
+				return "", err
+			}
+			fmt.Fprintf(h, "tagged entries %d", links.Count)
+			if links.Digest != nil {
+				fmt.Fprintf(h, " %s", *links.Digest)
+			}
+			fmt.Fprintln(h)
+		}
+	}
+	if err := writeGoalsFingerprint(h, db, filter.UserID, goals, excuses, filter.StartDate, filter.EndDate, scopes...); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+func hasAnyTag(goal models.Goal, tagIDs []uuid.UUID) bool {
+	for _, tag := range goal.Tags {
+		for _, id := range tagIDs {
+			if tag.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
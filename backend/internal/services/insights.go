@@ -0,0 +1,64 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// Insights breaks the filtered goals' consistency down by weekday and month,
+// and their entries by the hour they were created in loc, with plain-language
+// findings. Days after today in loc are left out.
+func Insights(db *gorm.DB, filter models.HeatmapFilter, loc *time.Location) (*models.InsightsResponse, error) {
+	start, end := filter.StartDate, filter.EndDate
+	today := models.LocalDate(time.Now(), loc)
+	if end.After(today) {
+		end = today
+	}
+
+	goals, err := HeatmapGoals(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	goalIDs := make([]uuid.UUID, 0, len(goals))
+	goalsByID := make(map[uuid.UUID]*models.Goal, len(goals))
+	for i := range goals {
+		goalIDs = append(goalIDs, goals[i].ID)
+		goalsByID[goals[i].ID] = &goals[i]
+	}
+
+	hours := models.NewHourInsights()
+	rates := make(map[uuid.UUID]map[string]float64, len(goals))
+	if len(goalIDs) > 0 {
+		var entries []models.Progress
+		if err := db.Where("user_id = ? AND goal_id IN ? AND tracked_date BETWEEN ? AND ?", filter.UserID, goalIDs, start, end).
+			Preload("Checks").
+			Order("tracked_date ASC, logged_at ASC").
+			Find(&entries).Error; err != nil {
+			return nil, err
+		}
+
+		entriesByGoal := make(map[uuid.UUID][]models.Progress)
+		for _, entry := range entries {
+			entriesByGoal[entry.GoalID] = append(entriesByGoal[entry.GoalID], entry)
+			hours[entry.CreatedAt.In(loc).Hour()].AddEntry(entry.CompletionRate)
+		}
+		for goalID, goalEntries := range entriesByGoal {
+			rates[goalID] = make(map[string]float64)
+			for _, day := range goalsByID[goalID].AggregateDaily(goalEntries) {
+				rates[goalID][models.DateKey(day.Date)] = day.CompletionRate
+			}
+		}
+	}
+
+	excuses, err := GoalExcuses(db, filter.UserID, goalIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	insights := models.BuildInsights(goals, rates, excuses, hours, start, end, today)
+	insights.Timezone = loc.String()
+	return insights, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/pomodoro"
+)
+
+// PomodoroService stores finished focus intervals and credits completed ones
+// to their time goal. It is the engine's pomodoro.Recorder.
+type PomodoroService struct {
+	db *gorm.DB
+}
+
+func NewPomodoroService(db *gorm.DB) *PomodoroService {
+	return &PomodoroService{db: db}
+}
+
+func (s *PomodoroService) RecordInterval(interval pomodoro.Interval) error {
+	var goal models.Goal
+	if err := s.db.Where("id = ? AND user_id = ?", interval.GoalID, interval.UserID).First(&goal).Error; err != nil {
+		return err
+	}
+	var user models.User
+	if err := s.db.Select("timezone").First(&user, interval.UserID).Error; err != nil {
+		return err
+	}
+
+	y, m, d := interval.StartedAt.In(user.Location()).Date()
+	trackedDate := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	minutes := math.Round(interval.EndedAt.Sub(interval.StartedAt).Minutes()*100) / 100
+
+	record := models.PomodoroInterval{
+		ID:             uuid.New(),
+		UserID:         interval.UserID,
+		GoalID:         interval.GoalID,
+		StartedAt:      interval.StartedAt,
+		EndedAt:        interval.EndedAt,
+		TrackedDate:    trackedDate,
+		PlannedMinutes: interval.Planned.Minutes(),
+		ActualMinutes:  minutes,
+		Completed:      interval.Completed,
+		CreatedAt:      time.Now(),
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if interval.Completed && minutes > 0 {
+			entry := models.Progress{
+				ID:          uuid.New(),
+				GoalID:      goal.ID,
+				UserID:      goal.UserID,
+				Value:       minutes,
+				Notes:       "Pomodoro",
+				TrackedDate: trackedDate,
+				LoggedAt:    interval.EndedAt,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			}
+			entry.CalculateCompletionRate(&goal)
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+			record.ProgressID = &entry.ID
+		}
+		return tx.Create(&record).Error
+	})
+}
+
+// DailyStats counts completed and interrupted focus intervals per day between
+// start and end, inclusive, optionally for one goal
+func (s *PomodoroService) DailyStats(userID uuid.UUID, goalID *uuid.UUID, start, end time.Time) ([]models.PomodoroDayStats, error) {
+	query := s.db.Model(&models.PomodoroInterval{}).
+		Select(`tracked_date as date,
+			COUNT(*) FILTER (WHERE completed) as completed,
+			COUNT(*) FILTER (WHERE NOT completed) as interrupted,
+			COALESCE(SUM(actual_minutes) FILTER (WHERE completed), 0) as focus_minutes`).
+		Where("user_id = ? AND tracked_date BETWEEN ? AND ?", userID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if goalID != nil {
+		query = query.Where("goal_id = ?", *goalID)
+	}
+
+	stats := []models.PomodoroDayStats{}
+	if err := query.Group("tracked_date").Order("tracked_date ASC").Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/palette"
+)
+
+// ScoreSeries computes the weighted daily score over the filtered goals,
+// rolled up into weeks or months when asked. The combined series has no goal
+// color, so levels and colors follow the user's palette.
+func ScoreSeries(db *gorm.DB, filter models.HeatmapFilter, rollup models.ScoreRollup) ([]models.ScorePoint, error) {
+	var user models.User
+	if err := db.Select("heatmap_palette").First(&user, filter.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	goals, err := HeatmapGoals(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	goalIDs := make([]uuid.UUID, 0, len(goals))
+	goalsByID := make(map[uuid.UUID]*models.Goal, len(goals))
+	for i := range goals {
+		goalIDs = append(goalIDs, goals[i].ID)
+		goalsByID[goals[i].ID] = &goals[i]
+	}
+	if len(goalIDs) == 0 {
+		return []models.ScorePoint{}, nil
+	}
+	// Days still to come have nothing to score yet
+	start, end := filter.StartDate, filter.EndDate
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+
+	var entries []models.Progress
+	if err := db.Where("user_id = ? AND goal_id IN ? AND tracked_date BETWEEN ? AND ?", filter.UserID, goalIDs, start, end).
+		Preload("Checks").
+		Order("tracked_date ASC, logged_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	entriesByGoal := make(map[uuid.UUID][]models.Progress)
+	for _, entry := range entries {
+		entriesByGoal[entry.GoalID] = append(entriesByGoal[entry.GoalID], entry)
+	}
+	rates := make(map[uuid.UUID]map[string]float64, len(goals))
+	for goalID, goalEntries := range entriesByGoal {
+		rates[goalID] = make(map[string]float64)
+		for _, day := range goalsByID[goalID].AggregateDaily(goalEntries) {
+			rates[goalID][models.DateKey(day.Date)] = day.CompletionRate
+		}
+	}
+
+	excuses, err := GoalExcuses(db, filter.UserID, goalIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	days := models.DailyScores(goals, rates, excuses, start, end)
+	points := models.RollupScores(days, rollup)
+
+	scorePalette := palette.Resolve(user.HeatmapPalette)
+	for i := range points {
+		points[i].IntensityLevel = scorePalette.Level(points[i].Score)
+		points[i].Color = scorePalette.Color(points[i].IntensityLevel)
+	}
+	return points, nil
+}
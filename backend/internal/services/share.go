@@ -0,0 +1,193 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/heatmap"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// NewShareToken returns a random, URL-safe token. It is not derived from
+// anything, so a link can't be traced back to its owner.
+func NewShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ShareFilter is the heatmap filter a link stands for
+func ShareFilter(link *models.ShareLink, start, end time.Time) models.HeatmapFilter {
+	filter := models.HeatmapFilter{
+		UserID:    link.UserID,
+		StartDate: start,
+		EndDate:   end,
+	}
+	switch link.Scope {
+	case models.ShareScopeGoal:
+		filter.GoalID = link.GoalID
+	case models.ShareScopeGroup:
+		filter.GroupID = link.GroupID
+	}
+	return filter
+}
+
+// ShareCalendar builds the calendar a link renders as SVG or PNG. Tooltips
+// keep only the date when the link hides values.
+func ShareCalendar(db *gorm.DB, link *models.ShareLink, start, end time.Time) (heatmap.Calendar, error) {
+	calendar, err := HeatmapCalendar(db, ShareFilter(link, start, end))
+	if err != nil {
+		return calendar, err
+	}
+
+	switch {
+	case link.Title != "":
+		calendar.Title = link.Title
+	case link.Scope == models.ShareScopeGroup:
+		var group models.GoalGroup
+		if err := db.Select("name").Where("id = ? AND user_id = ?", link.GroupID, link.UserID).First(&group).Error; err != nil {
+			return calendar, err
+		}
+		calendar.Title = group.Name
+	}
+
+	if !link.ShowsValues() {
+		for key, day := range calendar.Days {
+			day.Title = day.Date.Format("Jan 2, 2006")
+			if day.Excused {
+				day.Title += ": excused"
+			}
+			calendar.Days[key] = day
+		}
+	}
+	return calendar, nil
+}
+
+// ShareFingerprint identifies the calendar ShareCalendar builds for the link on
+// the given day, like HeatmapFingerprint, adding the link's own settings and
+// the name of a shared group
+func ShareFingerprint(db *gorm.DB, link *models.ShareLink, start, end, today time.Time) (string, error) {
+	fingerprint, err := HeatmapFingerprint(db, ShareFilter(link, start, end), today)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\nlink %s %d\n", fingerprint, link.ID, link.UpdatedAt.UnixNano())
+	if link.Scope == models.ShareScopeGroup && link.Title == "" {
+		var group models.GoalGroup
+		if err := db.Select("name").Where("id = ? AND user_id = ?", link.GroupID, link.UserID).First(&group).Error; err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "group %q\n", group.Name)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+// PublicHeatmap is a link's JSON view: a goal's days, or the daily score of a
+// group or of every goal, stripped of whatever the link hides
+func PublicHeatmap(db *gorm.DB, link *models.ShareLink, start, end time.Time) (*models.PublicHeatmap, error) {
+	calendar, err := ShareCalendar(db, link, start, end)
+	if err != nil {
+		return nil, err
+	}
+	streak, err := ShareStreak(db, link)
+	if err != nil {
+		return nil, err
+	}
+
+	public := &models.PublicHeatmap{
+		Title:         calendar.Title,
+		Scope:         link.Scope,
+		StartDate:     start,
+		EndDate:       end,
+		CurrentStreak: streak,
+		Legend:        calendar.Legend,
+		Days:          []models.PublicHeatmapDay{},
+	}
+
+	filter := ShareFilter(link, start, end)
+	if link.Scope != models.ShareScopeGoal {
+		points, err := ScoreSeries(db, filter, models.ScoreRollupDay)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range points {
+			day := models.PublicHeatmapDay{
+				Date:           point.Date,
+				IntensityLevel: point.IntensityLevel,
+				Color:          point.Color,
+			}
+			if !link.IntensityOnly {
+				score := point.Score
+				day.CompletionRate = &score
+			}
+			public.Days = append(public.Days, day)
+		}
+		return public, nil
+	}
+
+	cells, err := HeatmapCells(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, cell := range cells {
+		day := models.PublicHeatmapDay{
+			Date:           cell.Date,
+			IntensityLevel: cell.IntensityLevel,
+			Color:          cell.Color,
+			Excused:        cell.Excused,
+		}
+		if !link.IntensityOnly {
+			rate := cell.CompletionRate
+			day.GoalTitle = cell.GoalTitle
+			day.CompletionRate = &rate
+		}
+		if link.ShowsValues() {
+			day.FormattedValue = cell.FormattedValue
+		}
+		if link.ShowsNotes() {
+			day.Notes = cell.Notes
+		}
+		public.Days = append(public.Days, day)
+	}
+	sort.SliceStable(public.Days, func(i, j int) bool { return public.Days[i].Date.Before(public.Days[j].Date) })
+	return public, nil
+}
+
+// ShareStreak is the current streak a link's badge shows: the goal's own, or
+// the best among the active goals of a group or of every goal
+func ShareStreak(db *gorm.DB, link *models.ShareLink) (int, error) {
+	query := db.Select("id", "created_at").Where("user_id = ?", link.UserID)
+	switch link.Scope {
+	case models.ShareScopeGoal:
+		query = query.Where("id = ?", link.GoalID)
+	case models.ShareScopeGroup:
+		query = query.Where("group_id = ? AND is_active = ?", link.GroupID, true)
+	default:
+		query = query.Where("is_active = ?", true)
+	}
+	var goals []models.Goal
+	if err := query.Find(&goals).Error; err != nil {
+		return 0, err
+	}
+
+	validation := NewValidationService(db)
+	best := 0
+	for _, goal := range goals {
+		summary, err := validation.GetProgressSummary(goal.ID, link.UserID, goal.CreatedAt, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("summarising goal %s: %w", goal.ID, err)
+		}
+		best = max(best, summary.CurrentStreak)
+	}
+	return best, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// ResolveTags loads the user's tags with the given IDs, failing if any of them
+// doesn't exist or belongs to someone else
+func ResolveTags(db *gorm.DB, userID uuid.UUID, tagIDs []uuid.UUID) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if len(tagIDs) == 0 {
+		return tags, nil
+	}
+	if err := db.Where("user_id = ? AND id IN ?", userID, tagIDs).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uuid.UUID]bool, len(tags))
+	for _, tag := range tags {
+		found[tag.ID] = true
+	}
+	for _, id := range tagIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("tag %s not found", id)
+		}
+	}
+	return tags, nil
+}
+
+// TagFilterIDs turns tag query values, given as IDs or names, into the user's tag IDs.
+// Unknown tags match nothing rather than being ignored.
+func TagFilterIDs(db *gorm.DB, userID uuid.UUID, values []string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	var names []string
+	for _, value := range values {
+		if id, err := uuid.Parse(value); err == nil {
+			ids = append(ids, id)
+		} else if value != "" {
+			names = append(names, value)
+		}
+	}
+
+	query := db.Model(&models.Tag{}).Where("user_id = ?", userID)
+	switch {
+	case len(ids) > 0 && len(names) > 0:
+		query = query.Where("id IN ? OR name IN ?", ids, names)
+	case len(ids) > 0:
+		query = query.Where("id IN ?", ids)
+	case len(names) > 0:
+		query = query.Where("name IN ?", names)
+	default:
+		return nil, nil
+	}
+
+	matched := []uuid.UUID{}
+	if err := query.Pluck("id", &matched).Error; err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+// TagSummaries totals each of the user's tags over the date range. An entry
+// counts for the tags on it and the tags on its goal.
+func TagSummaries(db *gorm.DB, userID uuid.UUID, start, end time.Time) ([]models.TagSummary, error) {
+	var tags []models.Tag
+	if err := db.Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	var goals []models.Goal
+	if err := db.Where("user_id = ?", userID).
+		Preload("Tags").
+		Preload("MetricType").
+		Preload("ChecklistItems", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, created_at ASC") }).
+		Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	goalsByID := make(map[uuid.UUID]*models.Goal, len(goals))
+	for i := range goals {
+		goalsByID[goals[i].ID] = &goals[i]
+	}
+
+	var entries []models.Progress
+	if err := db.Where("user_id = ? AND tracked_date BETWEEN ? AND ?", userID, start, end).
+		Preload("Tags").
+		Preload("Checks").
+		Order("tracked_date ASC, logged_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	// tag -> goal -> entries carrying the tag
+	tagged := make(map[uuid.UUID]map[uuid.UUID][]models.Progress)
+	for _, entry := range entries {
+		goal, ok := goalsByID[entry.GoalID]
+		if !ok {
+			continue
+		}
+		for tagID := range entry.TagIDs(goal) {
+			if tagged[tagID] == nil {
+				tagged[tagID] = make(map[uuid.UUID][]models.Progress)
+			}
+			tagged[tagID][goal.ID] = append(tagged[tagID][goal.ID], entry)
+		}
+	}
+
+	summaries := make([]models.TagSummary, 0, len(tags))
+	for _, tag := range tags {
+		summary := models.TagSummary{TagID: tag.ID, Name: tag.Name, ColorCode: tag.ColorCode}
+		for _, goal := range goals {
+			for _, goalTag := range goal.Tags {
+				if goalTag.ID == tag.ID {
+					summary.GoalCount++
+				}
+			}
+		}
+
+		days := make(map[string]bool)
+		var goalDays int
+		var totalCompletion float64
+		for goalID, goalEntries := range tagged[tag.ID] {
+			goal := goalsByID[goalID]
+			summary.EntryCount += len(goalEntries)
+			for _, day := range goal.AggregateDaily(goalEntries) {
+				days[models.DateKey(day.Date)] = true
+				goalDays++
+				totalCompletion += day.CompletionRate
+				if goal.Type == models.GoalTypeTime {
+					summary.TotalMinutes += day.Value
+				}
+			}
+		}
+		summary.TrackedDays = len(days)
+		if goalDays > 0 {
+			summary.AverageCompletion = totalCompletion / float64(goalDays)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+var (
+	ErrTimerAlreadyActive = errors.New("a timer is already active")
+	ErrNoActiveTimer      = errors.New("no active timer for this goal")
+	ErrTimerNotRunning    = errors.New("timer is not running")
+	ErrTimerNotPaused     = errors.New("timer is not paused")
+	ErrNotTimeGoal        = errors.New("timers are only available for time goals")
+)
+
+type TimerService struct {
+	db *gorm.DB
+}
+
+func NewTimerService(db *gorm.DB) *TimerService {
+	return &TimerService{db: db}
+}
+
+// Active returns the user's running or paused timer, or nil when there is none
+func (s *TimerService) Active(userID uuid.UUID) (*models.TimerSession, error) {
+	var session models.TimerSession
+	err := s.db.Where("user_id = ? AND stopped_at IS NULL", userID).
+		Preload("Segments", func(db *gorm.DB) *gorm.DB { return db.Order("started_at ASC") }).
+		First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *TimerService) Start(goal *models.Goal, notes string, now time.Time) (*models.TimerSession, error) {
+	if goal.Type != models.GoalTypeTime {
+		return nil, ErrNotTimeGoal
+	}
+
+	active, err := s.Active(goal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrTimerAlreadyActive
+	}
+
+	session := models.TimerSession{
+		ID:        uuid.New(),
+		UserID:    goal.UserID,
+		GoalID:    goal.ID,
+		State:     models.TimerStateRunning,
+		StartedAt: now,
+		Notes:     notes,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Segments:  []models.TimerSegment{{ID: uuid.New(), StartedAt: now}},
+	}
+	// A concurrent start loses on the partial unique index
+	if err := s.db.Create(&session).Error; err != nil {
+		if active, _ := s.Active(goal.UserID); active != nil {
+			return nil, ErrTimerAlreadyActive
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *TimerService) Pause(userID, goalID uuid.UUID, now time.Time) (*models.TimerSession, error) {
+	session, err := s.activeFor(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	segment := session.OpenSegment()
+	if session.State != models.TimerStateRunning || segment == nil {
+		return nil, ErrTimerNotRunning
+	}
+
+	segment.EndedAt = &now
+	session.State = models.TimerStatePaused
+	session.UpdatedAt = now
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(segment).Update("ended_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(session).Updates(map[string]interface{}{"state": session.State, "updated_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *TimerService) Resume(userID, goalID uuid.UUID, now time.Time) (*models.TimerSession, error) {
+	session, err := s.activeFor(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if session.State != models.TimerStatePaused {
+		return nil, ErrTimerNotPaused
+	}
+
+	segment := models.TimerSegment{ID: uuid.New(), SessionID: session.ID, StartedAt: now}
+	session.State = models.TimerStateRunning
+	session.UpdatedAt = now
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&segment).Error; err != nil {
+			return err
+		}
+		return tx.Model(session).Updates(map[string]interface{}{"state": session.State, "updated_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	session.Segments = append(session.Segments, segment)
+	return session, nil
+}
+
+// Stop closes the timer and records its running time as time progress, one
+// entry per local day the session touched
+func (s *TimerService) Stop(goal *models.Goal, notes *string, loc *time.Location, now time.Time) (*models.TimerSession, []models.Progress, error) {
+	session, err := s.activeFor(goal.UserID, goal.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if notes != nil {
+		session.Notes = *notes
+	}
+
+	var entries []models.Progress
+	for _, day := range session.SplitByDay(loc, now) {
+		minutes := math.Round(day.Minutes*100) / 100
+		if minutes <= 0 {
+			continue
+		}
+		entry := models.Progress{
+			ID:          uuid.New(),
+			GoalID:      goal.ID,
+			UserID:      goal.UserID,
+			Value:       minutes,
+			Notes:       session.Notes,
+			TrackedDate: day.Date,
+			LoggedAt:    day.LastSeen,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		entry.CalculateCompletionRate(goal)
+		entries = append(entries, entry)
+	}
+
+	if segment := session.OpenSegment(); segment != nil {
+		segment.EndedAt = &now
+	}
+	session.State = models.TimerStateStopped
+	session.StoppedAt = &now
+	session.UpdatedAt = now
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TimerSegment{}).Where("session_id = ? AND ended_at IS NULL", session.ID).
+			Update("ended_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(session).Updates(map[string]interface{}{
+			"state":      session.State,
+			"stopped_at": now,
+			"notes":      session.Notes,
+			"updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return tx.Create(&entries).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, entries, nil
+}
+
+func (s *TimerService) activeFor(userID, goalID uuid.UUID) (*models.TimerSession, error) {
+	session, err := s.Active(userID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.GoalID != goalID {
+		return nil, ErrNoActiveTimer
+	}
+	return session, nil
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// Trends are computed in SQL so that moving averages over years of history
+// don't load every entry. Each goal's scoring parameters are passed in as a
+// VALUES list, its days are derived from the progress table the way
+// Goal.AggregateDaily derives them, and window functions average over calendar
+// days. Checklist goals take the best entry completion of the day instead of
+// combining check-offs across entries.
+
+// trendGoalColumns are the goal_params columns, in the order trendGoalRow fills them
+const trendGoalColumns = "goal_id, aggregation, kind, target, upper, missing, schedule, weekdays, every, anchor, since, until"
+
+const trendGoalRow = "(?::uuid, ?::text, ?::text, ?::float8, ?::float8, ?::float8, ?::text, ?::int, ?::int, ?::date, ?::date, ?::date)"
+
+// trendSeriesSQL ends in the series CTE: one row per goal per day from the
+// first to the second generate_series bound on which the goal was live or
+// logged. completion is NULL on unscheduled days, and on excused days, days
+// from today on and weekly quota days without entries; value is NULL on days
+// without entries unless the goal sums its entries. Excused days are those of excused periods and
+// spent freeze tokens, as in GoalExcuses.
+const trendSeriesSQL = `WITH goal_params (` + trendGoalColumns + `) AS (
+	VALUES %s
+),
+daily AS (
+	SELECT p.goal_id, (p.tracked_date AT TIME ZONE 'UTC')::date AS day,
+		CASE gp.aggregation
+			WHEN 'max' THEN MAX(p.value)
+			WHEN 'avg' THEN AVG(p.value)
+			WHEN 'last' THEN (ARRAY_AGG(p.value ORDER BY p.logged_at DESC))[1]
+			ELSE SUM(p.value)
+		END AS value,
+		MAX(p.completion_rate) AS entry_rate
+	FROM progress p
+	JOIN goal_params gp ON gp.goal_id = p.goal_id
+	WHERE p.user_id = ? AND p.tracked_date BETWEEN ? AND ?
+	GROUP BY p.goal_id, day, gp.aggregation
+),
+rated AS (
+	SELECT d.goal_id, d.day, d.value,
+		GREATEST(0, LEAST(100, CASE gp.kind
+			WHEN 'checklist' THEN d.entry_rate
+			WHEN 'rating' THEN (d.value - gp.target) / NULLIF(gp.upper - gp.target, 0) * 100
+			WHEN 'at_most' THEN CASE WHEN d.value <= gp.target THEN 100 ELSE gp.target / d.value * 100 END
+			WHEN 'range' THEN CASE
+				WHEN d.value < gp.target THEN d.value / gp.target * 100
+				WHEN d.value > gp.upper THEN gp.upper / d.value * 100
+				ELSE 100
+			END
+			ELSE CASE WHEN gp.target > 0 THEN d.value / gp.target * 100 ELSE 0 END
+		END)) AS completion
+	FROM daily d
+	JOIN goal_params gp ON gp.goal_id = d.goal_id
+),
+excused AS (
+	SELECT gp.goal_id, e.day::date AS day
+	FROM goal_params gp
+	JOIN excused_periods ep ON ep.user_id = ? AND (ep.goal_id IS NULL OR ep.goal_id = gp.goal_id)
+		AND ep.start_date <= ?::date AND ep.end_date >= ?::date
+	CROSS JOIN LATERAL generate_series(ep.start_date, ep.end_date, INTERVAL '1 day') AS e (day)
+	UNION
+	SELECT ft.used_goal_id, ft.used_on
+	FROM freeze_tokens ft
+	JOIN goal_params gp ON gp.goal_id = ft.used_goal_id
+	WHERE ft.user_id = ? AND ft.used_on BETWEEN ? AND ?
+),
+series AS (
+	SELECT gp.goal_id, c.day::date AS day, r.goal_id IS NOT NULL AS logged,
+		CASE WHEN r.goal_id IS NOT NULL THEN r.value WHEN gp.aggregation = 'sum' THEN 0 END AS value,
+		CASE
+			WHEN r.goal_id IS NOT NULL THEN r.completion
+			WHEN x.goal_id IS NOT NULL OR c.day::date >= ?::date THEN NULL
+			WHEN CASE gp.schedule
+				WHEN 'weekdays' THEN (gp.weekdays & (1 << EXTRACT(DOW FROM c.day)::int)) <> 0
+				WHEN 'interval' THEN gp.every <= 1 OR (c.day::date >= gp.anchor AND (c.day::date - gp.anchor) %% NULLIF(gp.every, 0) = 0)
+				WHEN 'times_per_week' THEN false
+				ELSE true
+			END THEN gp.missing
+		END AS completion
+	FROM goal_params gp
+	CROSS JOIN generate_series(?::date, ?::date, INTERVAL '1 day') AS c (day)
+	LEFT JOIN rated r ON r.goal_id = gp.goal_id AND r.day = c.day::date
+	LEFT JOIN excused x ON x.goal_id = gp.goal_id AND x.day = c.day::date
+	WHERE r.goal_id IS NOT NULL OR (c.day::date >= gp.since AND (gp.until IS NULL OR c.day::date <= gp.until))
+)
+`
+
+// trendSeries builds the series CTE over from to to, with today being the first
+// day not yet counted as missed
+func trendSeries(goals []models.Goal, userID uuid.UUID, from, to, today time.Time) (string, []any) {
+	rows := make([]string, 0, len(goals))
+	args := make([]any, 0, len(goals)*12+12)
+	for i := range goals {
+		goal := &goals[i]
+		kind, target, upper := string(goal.Direction), goal.DailyTarget(), goal.Target
+		switch {
+		case goal.IsChecklist():
+			kind = "checklist"
+		case goal.Type == models.GoalTypeRating:
+			kind, target, upper = "rating", float64(goal.ScaleMin), float64(goal.ScaleMax)
+		case goal.Direction == models.GoalDirectionAtMost:
+			target = goal.Target
+		case goal.Direction == models.GoalDirectionRange:
+			target = goal.Target
+			if goal.TargetMax != nil {
+				upper = *goal.TargetMax
+			}
+		default:
+			kind = string(models.GoalDirectionAtLeast)
+		}
+
+		since := goal.CreatedAt
+		if goal.StartDate != nil && goal.StartDate.After(since) {
+			since = *goal.StartDate
+		}
+		rows = append(rows, trendGoalRow)
+		args = append(args, goal.ID, string(goal.DailyAggregation()), kind, target, upper,
+			goal.MissingEntryCompletion(), string(goal.ScheduleType), int(goal.ScheduleWeekdays),
+			goal.ScheduleInterval, goal.ScheduleAnchor(), models.DateKey(since), goal.EndDate)
+	}
+	args = append(args, userID, from, to, userID, to, from, userID, from, to, today, from, to)
+	return fmt.Sprintf(trendSeriesSQL, strings.Join(rows, ",\n\t")), args
+}
+
+// trendRow is a series day with the moving averages ending on it and the ones
+// a window earlier
+type trendRow struct {
+	GoalID                  uuid.UUID
+	Day                     time.Time
+	Logged                  bool
+	Value                   *float64
+	Completion              *float64
+	CompletionAvg7          *float64 `gorm:"column:completion_avg_7"`
+	CompletionAvg30         *float64 `gorm:"column:completion_avg_30"`
+	ValueAvg7               *float64 `gorm:"column:value_avg_7"`
+	ValueAvg30              *float64 `gorm:"column:value_avg_30"`
+	PreviousCompletionAvg7  *float64 `gorm:"column:previous_completion_avg_7"`
+	PreviousCompletionAvg30 *float64 `gorm:"column:previous_completion_avg_30"`
+	PreviousValueAvg7       *float64 `gorm:"column:previous_value_avg_7"`
+	PreviousValueAvg30      *float64 `gorm:"column:previous_value_avg_30"`
+}
+
+// trendWindow averages column over the days from `from` to `to` days before each row
+func trendWindow(column string, from, to int) string {
+	end := "CURRENT ROW"
+	if to > 0 {
+		end = fmt.Sprintf("INTERVAL '%d days' PRECEDING", to)
+	}
+	return fmt.Sprintf("AVG(%s) OVER (w RANGE BETWEEN INTERVAL '%d days' PRECEDING AND %s)", column, from, end)
+}
+
+var trendSelectSQL = fmt.Sprintf(`SELECT * FROM (
+	SELECT goal_id, day, logged, value, completion,
+		%s AS completion_avg_7,
+		%s AS completion_avg_30,
+		%s AS value_avg_7,
+		%s AS value_avg_30,
+		%s AS previous_completion_avg_7,
+		%s AS previous_completion_avg_30,
+		%s AS previous_value_avg_7,
+		%s AS previous_value_avg_30
+	FROM series
+	WINDOW w AS (PARTITION BY goal_id ORDER BY day)
+) trends
+WHERE day >= ?
+ORDER BY goal_id, day`,
+	trendWindow("completion", models.ShortTrendWindow-1, 0),
+	trendWindow("completion", models.LongTrendWindow-1, 0),
+	trendWindow("value", models.ShortTrendWindow-1, 0),
+	trendWindow("value", models.LongTrendWindow-1, 0),
+	trendWindow("completion", 2*models.ShortTrendWindow-1, models.ShortTrendWindow),
+	trendWindow("completion", 2*models.LongTrendWindow-1, models.LongTrendWindow),
+	trendWindow("value", 2*models.ShortTrendWindow-1, models.ShortTrendWindow),
+	trendWindow("value", 2*models.LongTrendWindow-1, models.LongTrendWindow),
+)
+
+// periodRow holds a goal's averages over the two compared periods
+type periodRow struct {
+	GoalID      uuid.UUID
+	ALoggedDays int      `gorm:"column:a_logged_days"`
+	ACompletion *float64 `gorm:"column:a_completion"`
+	AValue      *float64 `gorm:"column:a_value"`
+	ATotal      float64  `gorm:"column:a_total"`
+	BLoggedDays int      `gorm:"column:b_logged_days"`
+	BCompletion *float64 `gorm:"column:b_completion"`
+	BValue      *float64 `gorm:"column:b_value"`
+	BTotal      float64  `gorm:"column:b_total"`
+}
+
+const periodSelectSQL = `SELECT goal_id,
+	COUNT(*) FILTER (WHERE logged AND day BETWEEN ? AND ?) AS a_logged_days,
+	AVG(completion) FILTER (WHERE day BETWEEN ? AND ?) AS a_completion,
+	AVG(value) FILTER (WHERE day BETWEEN ? AND ?) AS a_value,
+	COALESCE(SUM(value) FILTER (WHERE day BETWEEN ? AND ?), 0) AS a_total,
+	COUNT(*) FILTER (WHERE logged AND day BETWEEN ? AND ?) AS b_logged_days,
+	AVG(completion) FILTER (WHERE day BETWEEN ? AND ?) AS b_completion,
+	AVG(value) FILTER (WHERE day BETWEEN ? AND ?) AS b_value,
+	COALESCE(SUM(value) FILTER (WHERE day BETWEEN ? AND ?), 0) AS b_total
+FROM series
+GROUP BY goal_id`
+
+// Trends computes each goal's daily series from start to end with 7- and 30-day
+// moving averages, and the week-over-week and month-over-month change at the
+// end of the range. With both periods given, each goal also compares period b
+// against period a. today is the user's current date.
+func Trends(db *gorm.DB, filter models.HeatmapFilter, a, b *models.TrendPeriod, today time.Time) (*models.TrendsResponse, error) {
+	goals, err := HeatmapGoals(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(goals, func(i, j int) bool {
+		if goals[i].SortOrder != goals[j].SortOrder {
+			return goals[i].SortOrder < goals[j].SortOrder
+		}
+		return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+	})
+
+	response := &models.TrendsResponse{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Goals:     []models.GoalTrend{},
+	}
+	if len(goals) == 0 {
+		return response, nil
+	}
+
+	// Warm up with enough history for the previous 30-day window on the first day
+	from := filter.StartDate.AddDate(0, 0, -(2*models.LongTrendWindow - 1))
+	series, args := trendSeries(goals, filter.UserID, from, filter.EndDate, today)
+	var rows []trendRow
+	if err := db.Raw(series+trendSelectSQL, append(args, filter.StartDate)...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var periods map[uuid.UUID]periodRow
+	if a != nil && b != nil {
+		if periods, err = comparePeriods(db, goals, filter.UserID, *a, *b, today); err != nil {
+			return nil, err
+		}
+	}
+
+	rowsByGoal := make(map[uuid.UUID][]trendRow, len(goals))
+	for _, row := range rows {
+		rowsByGoal[row.GoalID] = append(rowsByGoal[row.GoalID], row)
+	}
+	for i := range goals {
+		goal := &goals[i]
+		trend := models.GoalTrend{
+			GoalID:    goal.ID,
+			GoalTitle: goal.Title,
+			Unit:      goal.Unit,
+			Series:    []models.TrendPoint{},
+		}
+		convert := func(value *float64) *float64 {
+			if value == nil {
+				return nil
+			}
+			converted := goal.FromBaseUnit(*value)
+			return &converted
+		}
+
+		goalRows := rowsByGoal[goal.ID]
+		for _, row := range goalRows {
+			trend.Series = append(trend.Series, models.TrendPoint{
+				Date:                row.Day,
+				Logged:              row.Logged,
+				Value:               convert(row.Value),
+				CompletionRate:      row.Completion,
+				CompletionAverage7:  row.CompletionAvg7,
+				CompletionAverage30: row.CompletionAvg30,
+				ValueAverage7:       convert(row.ValueAvg7),
+				ValueAverage30:      convert(row.ValueAvg30),
+			})
+		}
+		if n := len(goalRows); n > 0 && goalRows[n-1].Day.Equal(filter.EndDate) {
+			last := goalRows[n-1]
+			trend.WeekOverWeek = trendDelta(last.CompletionAvg7, last.PreviousCompletionAvg7,
+				convert(last.ValueAvg7), convert(last.PreviousValueAvg7))
+			trend.MonthOverMonth = trendDelta(last.CompletionAvg30, last.PreviousCompletionAvg30,
+				convert(last.ValueAvg30), convert(last.PreviousValueAvg30))
+		}
+
+		if periods != nil {
+			row := periods[goal.ID]
+			comparison := &models.PeriodComparison{
+				A: models.PeriodStats{
+					TrendPeriod:       *a,
+					LoggedDays:        row.ALoggedDays,
+					AverageCompletion: row.ACompletion,
+					AverageValue:      convert(row.AValue),
+					TotalValue:        goal.FromBaseUnit(row.ATotal),
+				},
+				B: models.PeriodStats{
+					TrendPeriod:       *b,
+					LoggedDays:        row.BLoggedDays,
+					AverageCompletion: row.BCompletion,
+					AverageValue:      convert(row.BValue),
+					TotalValue:        goal.FromBaseUnit(row.BTotal),
+				},
+			}
+			comparison.CompletionChange = change(comparison.B.AverageCompletion, comparison.A.AverageCompletion)
+			comparison.ValueChange = change(comparison.B.AverageValue, comparison.A.AverageValue)
+			trend.Comparison = comparison
+		}
+		response.Goals = append(response.Goals, trend)
+	}
+	return response, nil
+}
+
+// comparePeriods averages each goal over both periods in one pass over the
+// series spanning them
+func comparePeriods(db *gorm.DB, goals []models.Goal, userID uuid.UUID, a, b models.TrendPeriod, today time.Time) (map[uuid.UUID]periodRow, error) {
+	from, to := a.Start, a.End
+	if b.Start.Before(from) {
+		from = b.Start
+	}
+	if b.End.After(to) {
+		to = b.End
+	}
+	series, args := trendSeries(goals, userID, from, to, today)
+	for _, period := range []models.TrendPeriod{a, b} {
+		for range 4 {
+			args = append(args, period.Start, period.End)
+		}
+	}
+
+	var rows []periodRow
+	if err := db.Raw(series+periodSelectSQL, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	periods := make(map[uuid.UUID]periodRow, len(rows))
+	for _, row := range rows {
+		periods[row.GoalID] = row
+	}
+	return periods, nil
+}
+
+func trendDelta(completion, previousCompletion, value, previousValue *float64) models.TrendDelta {
+	return models.TrendDelta{
+		Completion:         completion,
+		PreviousCompletion: previousCompletion,
+		CompletionChange:   change(completion, previousCompletion),
+		Value:              value,
+		PreviousValue:      previousValue,
+		ValueChange:        change(value, previousValue),
+	}
+}
+
+// change is current minus previous, or nil when either is missing
+func change(current, previous *float64) *float64 {
+	if current == nil || previous == nil {
+		return nil
+	}
+	delta := *current - *previous
+	return &delta
+}
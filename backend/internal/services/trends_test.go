@@ -0,0 +1,327 @@
+package services
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"github.com/tarikozturk017/streak-map/backend/internal/database"
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+// testDB connects to the Postgres database named by TEST_DATABASE_DSN and
+// migrates it. Everything a test writes goes into a transaction that is
+// rolled back afterwards. Tests are skipped without a DSN.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if err := (&database.DB{DB: db}).AutoMigrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+// trendFixture is a user with goals of every kind, three weeks of entries
+// from 2026-03-01 to 2026-03-21, and excused days of both sources
+type trendFixture struct {
+	user          models.User
+	goals         []models.Goal
+	entriesByGoal map[uuid.UUID][]models.Progress
+}
+
+func newTrendFixture(t *testing.T, db *gorm.DB) *trendFixture {
+	t.Helper()
+	user := models.User{ID: uuid.New(), Email: uuid.NewString() + "@example.com", Username: uuid.NewString(), PasswordHash: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	created := date(t, "2026-02-20")
+	targetMax := 540.0
+	goals := []models.Goal{
+		{Title: "Study", Type: models.GoalTypeTime, Target: 60, Unit: "minutes"},
+		{Title: "Weight", Type: models.GoalTypeQuantity, Target: 80, Aggregation: models.AggregationLast, Direction: models.GoalDirectionAtMost},
+		{Title: "Sleep", Type: models.GoalTypeQuantity, Target: 420, TargetMax: &targetMax, Aggregation: models.AggregationAvg,
+			Direction: models.GoalDirectionRange, ScheduleType: models.ScheduleTypeWeekdays,
+			ScheduleWeekdays: models.NewWeekdaySet(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)},
+		{Title: "Mood", Type: models.GoalTypeRating, ScaleMin: 1, ScaleMax: 5, Aggregation: models.AggregationAvg,
+			ScheduleType: models.ScheduleTypeInterval, ScheduleInterval: 3},
+		{Title: "Run", Type: models.GoalTypeDistance, Target: 5000, Unit: "meters", Aggregation: models.AggregationMax},
+		{Title: "Swim", Type: models.GoalTypeQuantity, Target: 2, ScheduleType: models.ScheduleTypeTimesPerWeek, ScheduleTimesPerWeek: 3},
+	}
+	for i := range goals {
+		goal := &goals[i]
+		goal.ID = uuid.New()
+		goal.UserID = user.ID
+		goal.TrackingFrequency = models.TrackingFrequencyDaily
+		goal.IsActive = true
+		goal.Weight = 1
+		goal.CreatedAt = created
+		if goal.ScheduleType == "" {
+			goal.ScheduleType = models.ScheduleTypeDaily
+		}
+		if err := db.Create(goal).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from := date(t, "2026-03-01")
+	entriesByGoal := make(map[uuid.UUID][]models.Progress)
+	for offset := range 21 {
+		day := from.AddDate(0, 0, offset)
+		for j := range goals {
+			goal := &goals[j]
+			count := (offset*7 + j*3) % 4
+			if offset == 4 || offset == 9 || offset == 11 {
+				count *= j % 2 // Some excused days with entries, some without
+			}
+			for k := range count {
+				entry := models.Progress{
+					ID:          uuid.New(),
+					GoalID:      goal.ID,
+					UserID:      user.ID,
+					Value:       float64((offset*13+j*29+k*17)%9+1) * goal.Target / 6,
+					TrackedDate: day,
+					LoggedAt:    day.Add(time.Duration(8+k*3) * time.Hour),
+				}
+				if goal.Type == models.GoalTypeRating {
+					entry.Value = float64((offset+k+j)%5 + 1)
+				}
+				entry.CalculateCompletionRate(goal)
+				if err := db.Create(&entry).Error; err != nil {
+					t.Fatal(err)
+				}
+				entriesByGoal[goal.ID] = append(entriesByGoal[goal.ID], entry)
+			}
+		}
+	}
+
+	excusedPeriods := []models.ExcusedPeriod{
+		{ID: uuid.New(), UserID: user.ID, StartDate: date(t, "2026-03-05"), EndDate: date(t, "2026-03-06")},
+		{ID: uuid.New(), UserID: user.ID, GoalID: &goals[0].ID, StartDate: date(t, "2026-02-25"), EndDate: date(t, "2026-03-10")},
+	}
+	if err := db.Create(&excusedPeriods).Error; err != nil {
+		t.Fatal(err)
+	}
+	usedOn := date(t, "2026-03-12")
+	token := models.FreezeToken{ID: uuid.New(), UserID: user.ID, Source: models.FreezeSourceGranted, UsedGoalID: &goals[1].ID, UsedOn: &usedOn}
+	if err := db.Create(&token).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &trendFixture{user: user, goals: goals, entriesByGoal: entriesByGoal}
+}
+
+// series reads the fixture's series CTE, by goal and day
+func (f *trendFixture) series(t *testing.T, db *gorm.DB, from, to, today time.Time) map[uuid.UUID]map[string]trendRow {
+	t.Helper()
+	series, args := trendSeries(f.goals, f.user.ID, from, to, today)
+	var rows []trendRow
+	if err := db.Raw(series+"SELECT goal_id, day, logged, value, completion FROM series", args...).Scan(&rows).Error; err != nil {
+		t.Fatalf("series query failed: %v", err)
+	}
+	got := make(map[uuid.UUID]map[string]trendRow)
+	for _, row := range rows {
+		if got[row.GoalID] == nil {
+			got[row.GoalID] = make(map[string]trendRow)
+		}
+		got[row.GoalID][models.DateKey(row.Day)] = row
+	}
+	return got
+}
+
+// TestTrendSeriesMatchesAggregateDaily checks the SQL series against the days
+// Goal.AggregateDaily derives from the same entries, with missed, excused,
+// unscheduled, weekly quota and not yet counted days filled in like
+// countedCompletion does
+func TestTrendSeriesMatchesAggregateDaily(t *testing.T) {
+	db := testDB(t)
+	f := newTrendFixture(t, db)
+	goals := f.goals
+
+	from, to, today := date(t, "2026-03-01"), date(t, "2026-03-21"), date(t, "2026-03-20")
+	goalIDs := make([]uuid.UUID, len(goals))
+	for i := range goals {
+		goalIDs[i] = goals[i].ID
+	}
+	excuses, err := GoalExcuses(db, f.user.ID, goalIDs, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := f.series(t, db, from, to, today)
+
+	for i := range goals {
+		goal := &goals[i]
+		logged := make(map[string]models.DailyProgress)
+		for _, day := range goal.AggregateDaily(f.entriesByGoal[goal.ID]) {
+			logged[models.DateKey(day.Date)] = day
+		}
+
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			key := models.DateKey(day)
+			row, ok := got[goal.ID][key]
+			if !ok {
+				t.Errorf("%s %s: missing from the series", goal.Title, key)
+				continue
+			}
+
+			var wantValue, wantCompletion *float64
+			daily, wantLogged := logged[key]
+			if wantLogged {
+				wantValue, wantCompletion = &daily.Value, &daily.CompletionRate
+			} else {
+				if goal.DailyAggregation() == models.AggregationSum {
+					zero := 0.0
+					wantValue = &zero
+				}
+				_, excused := excuses[goal.ID][key]
+				if goal.IsScheduledOn(day) && goal.WeeklyQuota() == 0 && !excused && day.Before(today) {
+					missing := goal.MissingEntryCompletion()
+					wantCompletion = &missing
+				}
+			}
+
+			if row.Logged != wantLogged {
+				t.Errorf("%s %s: logged = %t, want %t", goal.Title, key, row.Logged, wantLogged)
+			}
+			if !sameValue(row.Value, wantValue) {
+				t.Errorf("%s %s: value = %s, want %s", goal.Title, key, formatValue(row.Value), formatValue(wantValue))
+			}
+			if !sameValue(row.Completion, wantCompletion) {
+				t.Errorf("%s %s: completion = %s, want %s", goal.Title, key, formatValue(row.Completion), formatValue(wantCompletion))
+			}
+		}
+	}
+}
+
+// TestTrendsAveragesTheSeries checks the moving averages and period comparisons
+// Trends reads through trendSelectSQL and comparePeriods against the same
+// series averaged here
+func TestTrendsAveragesTheSeries(t *testing.T) {
+	db := testDB(t)
+	f := newTrendFixture(t, db)
+
+	start, end, today := date(t, "2026-03-08"), date(t, "2026-03-21"), date(t, "2026-03-20")
+	a := models.TrendPeriod{Start: date(t, "2026-03-01"), End: date(t, "2026-03-07")}
+	b := models.TrendPeriod{Start: date(t, "2026-03-08"), End: date(t, "2026-03-14")}
+	filter := models.HeatmapFilter{UserID: f.user.ID, StartDate: start, EndDate: end}
+	trends, err := Trends(db, filter, &a, &b, today)
+	if err != nil {
+		t.Fatalf("Trends() = %v", err)
+	}
+	if len(trends.Goals) != len(f.goals) {
+		t.Fatalf("got %d goal trends, want %d", len(trends.Goals), len(f.goals))
+	}
+
+	series := f.series(t, db, date(t, "2026-01-01"), end, today)
+	// average averages a column of the series over from to to, both included
+	average := func(goalID uuid.UUID, from, to time.Time, column func(trendRow) *float64) *float64 {
+		var sum float64
+		var n int
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			row, ok := series[goalID][models.DateKey(day)]
+			if !ok || column(row) == nil {
+				continue
+			}
+			sum += *column(row)
+			n++
+		}
+		if n == 0 {
+			return nil
+		}
+		avg := sum / float64(n)
+		return &avg
+	}
+	completion := func(row trendRow) *float64 { return row.Completion }
+	value := func(row trendRow) *float64 { return row.Value }
+
+	for _, trend := range trends.Goals {
+		for _, point := range trend.Series {
+			key := models.DateKey(point.Date)
+			checks := []struct {
+				name      string
+				got, want *float64
+			}{
+				{"completion_avg_7", point.CompletionAverage7, average(trend.GoalID, point.Date.AddDate(0, 0, -6), point.Date, completion)},
+				{"completion_avg_30", point.CompletionAverage30, average(trend.GoalID, point.Date.AddDate(0, 0, -29), point.Date, completion)},
+				{"value_avg_7", point.ValueAverage7, average(trend.GoalID, point.Date.AddDate(0, 0, -6), point.Date, value)},
+				{"value_avg_30", point.ValueAverage30, average(trend.GoalID, point.Date.AddDate(0, 0, -29), point.Date, value)},
+			}
+			for _, c := range checks {
+				if !sameValue(c.got, c.want) {
+					t.Errorf("%s %s: %s = %s, want %s", trend.GoalTitle, key, c.name, formatValue(c.got), formatValue(c.want))
+				}
+			}
+		}
+
+		comparison := trend.Comparison
+		if comparison == nil {
+			t.Errorf("%s: no period comparison", trend.GoalTitle)
+			continue
+		}
+		for _, period := range []models.PeriodStats{comparison.A, comparison.B} {
+			var loggedDays int
+			var total float64
+			for day := period.Start; !day.After(period.End); day = day.AddDate(0, 0, 1) {
+				row, ok := series[trend.GoalID][models.DateKey(day)]
+				if ok && row.Logged {
+					loggedDays++
+				}
+				if ok && row.Value != nil {
+					total += *row.Value
+				}
+			}
+			label := trend.GoalTitle + " " + models.DateKey(period.Start)
+			if period.LoggedDays != loggedDays {
+				t.Errorf("%s: logged days = %d, want %d", label, period.LoggedDays, loggedDays)
+			}
+			if want := average(trend.GoalID, period.Start, period.End, completion); !sameValue(period.AverageCompletion, want) {
+				t.Errorf("%s: average completion = %s, want %s", label, formatValue(period.AverageCompletion), formatValue(want))
+			}
+			if want := average(trend.GoalID, period.Start, period.End, value); !sameValue(period.AverageValue, want) {
+				t.Errorf("%s: average value = %s, want %s", label, formatValue(period.AverageValue), formatValue(want))
+			}
+			if math.Abs(period.TotalValue-total) > 1e-6 {
+				t.Errorf("%s: total = %v, want %v", label, period.TotalValue, total)
+			}
+		}
+	}
+}
+
+func sameValue(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return math.Abs(*a-*b) < 1e-6
+}
+
+func formatValue(v *float64) string {
+	if v == nil {
+		return "NULL"
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
@@ -2,11 +2,14 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"github.com/tarikozturk017/streak-map/backend/internal/models"
+	"github.com/tarikozturk017/streak-map/backend/internal/streaks"
+	"github.com/tarikozturk017/streak-map/backend/internal/units"
 )
 
 type ValidationService struct {
@@ -29,47 +32,17 @@ func (v *ValidationService) ValidateGoalOwnership(goalID, userID uuid.UUID) erro
 }
 
 func (v *ValidationService) ValidateProgressInput(goal models.Goal, value float64) error {
+	// Hard limits come from the goal's metric definition
+	if err := goal.Metric().Validate(value); err != nil {
+		return err
+	}
+
 	switch goal.Type {
-	case models.GoalTypeTime:
-		if value < 0 || value > 24*60 { // Max 24 hours in minutes
-			return errors.New("time value must be between 0 and 1440 minutes (24 hours)")
-		}
-	case models.GoalTypeQuantity:
-		if value < 0 {
-			return errors.New("quantity value must be non-negative")
-		}
-		if value > goal.Target*10 { // Reasonable upper bound
-			return errors.New("quantity value seems unreasonably high")
-		}
-	case models.GoalTypeDistance:
-		if value < 0 {
-			return errors.New("distance value must be non-negative")
-		}
+	case models.GoalTypeQuantity, models.GoalTypeDistance:
 		if value > goal.Target*10 { // Reasonable upper bound
-			return errors.New("distance value seems unreasonably high")
+			return fmt.Errorf("%s value seems unreasonably high", goal.Type)
 		}
-	case models.GoalTypeBoolean:
-		if value != 0 && value != 1 {
-			return errors.New("boolean value must be 0 or 1")
-		}
-	}
-	return nil
-}
-
-func (v *ValidationService) ValidateProgressDate(goalID uuid.UUID, userID uuid.UUID, date time.Time, excludeProgressID *uuid.UUID) error {
-	query := v.db.Where("goal_id = ? AND user_id = ? AND DATE(tracked_date) = DATE(?)", goalID, userID, date)
-	
-	if excludeProgressID != nil {
-		query = query.Where("id != ?", *excludeProgressID)
-	}
-	
-	var existingProgress models.Progress
-	if err := query.First(&existingProgress).Error; err == nil {
-		return errors.New("progress entry already exists for this date")
-	} else if err != gorm.ErrRecordNotFound {
-		return err
 	}
-	
 	return nil
 }
 
@@ -83,8 +56,8 @@ func (v *ValidationService) ValidateGoalType(goalType models.GoalType, target fl
 		if target <= 0 || target > 24*60 {
 			return errors.New("time target must be between 1 and 1440 minutes")
 		}
-		if unit != "minutes" && unit != "hours" {
-			return errors.New("time goals must use 'minutes' or 'hours' as unit")
+		if u, err := units.Lookup(unit); err != nil || u.Dimension != units.DimensionTime {
+			return errors.New("time goals must use a time unit such as 'minutes' or 'hours'")
 		}
 	case models.GoalTypeQuantity:
 		if target <= 0 {
@@ -97,13 +70,21 @@ func (v *ValidationService) ValidateGoalType(goalType models.GoalType, target fl
 		if target <= 0 {
 			return errors.New("distance target must be positive")
 		}
-		if unit != "km" && unit != "miles" && unit != "meters" {
-			return errors.New("distance goals must use 'km', 'miles', or 'meters' as unit")
+		if u, err := units.Lookup(unit); err != nil || u.Dimension != units.DimensionDistance {
+			return errors.New("distance goals must use a distance unit such as 'km', 'miles', or 'meters'")
 		}
 	case models.GoalTypeBoolean:
 		if target != 1 {
 			return errors.New("boolean goals must have target of 1")
 		}
+	case models.GoalTypeRating:
+		if target < 0 {
+			return errors.New("rating target must be non-negative")
+		}
+	case models.GoalTypeCustom:
+		if target < 0 {
+			return errors.New("custom target must be non-negative")
+		}
 	}
 
 	return nil
@@ -137,100 +118,115 @@ func (v *ValidationService) ValidateTrackingFrequency(frequency models.TrackingF
 	return nil
 }
 
+// GetProgressSummary summarises a goal's progress from startDate to endDate.
+// Streaks come from the streaks package, walked over the goal's whole history
+// up to endDate so a range starting mid-streak doesn't cut it short, with
+// "today" being the user's today.
 func (v *ValidationService) GetProgressSummary(goalID uuid.UUID, userID uuid.UUID, startDate, endDate time.Time) (*models.ProgressSummary, error) {
 	var goal models.Goal
-	if err := v.db.Where("id = ? AND user_id = ?", goalID, userID).First(&goal).Error; err != nil {
+	if err := v.db.Where("id = ? AND user_id = ?", goalID, userID).Preload("MetricType").First(&goal).Error; err != nil {
+		return nil, err
+	}
+	if err := LoadChecklistItems(v.db, &goal); err != nil {
 		return nil, err
 	}
 
+	var user models.User
+	if err := v.db.Select("timezone").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	today := streaks.DateIn(time.Now(), user.Location())
+	startDate = streaks.DateOf(startDate).Time()
+	endDate = streaks.DateOf(endDate).Time()
+
 	var progressEntries []models.Progress
-	if err := v.db.Where("goal_id = ? AND user_id = ? AND tracked_date BETWEEN ? AND ?", 
-		goalID, userID, startDate, endDate).
-		Order("tracked_date ASC").
+	if err := v.db.Where("goal_id = ? AND user_id = ? AND tracked_date <= ?", 
+		goalID, userID, endDate).
+		Preload("Checks").
+		Order("tracked_date ASC, logged_at ASC").
 		Find(&progressEntries).Error; err != nil {
 		return nil, err
 	}
 
-	if len(progressEntries) == 0 {
-		return &models.ProgressSummary{
-			GoalID:    goalID,
-			GoalTitle: goal.Title,
-			GoalType:  goal.Type,
-		}, nil
+	summary := &models.ProgressSummary{
+		GoalID:     goalID,
+		GoalTitle:  goal.Title,
+		GoalType:   goal.Type,
+		StreakUnit: goal.StreakUnit(),
 	}
 
-	// Calculate summary statistics
+	// Limit goals keep their streak on days without entries, so they are
+	// summarised even before anything has been logged
+	if len(progressEntries) == 0 && goal.Direction != models.GoalDirectionAtMost {
+		return summary, nil
+	}
+
+	// Calculate summary statistics over the derived daily values, so several
+	// entries on one day count as that one day
+	days := goal.AggregateDaily(progressEntries)
 	var totalCompletion float64
 	var bestCompletion float64
-	currentStreak := 0
-	longestStreak := 0
-	tempStreak := 0
-
-	for i, entry := range progressEntries {
-		totalCompletion += entry.CompletionRate
-		if entry.CompletionRate > bestCompletion {
-			bestCompletion = entry.CompletionRate
-		}
-
-		// Calculate streaks (consecutive days with progress > 0)
-		if entry.CompletionRate > 0 {
-			// Check if this is consecutive to the previous entry
-			if i == 0 {
-				// First entry starts a streak
-				tempStreak = 1
-			} else {
-				prevDate := progressEntries[i-1].TrackedDate
-				currentDate := entry.TrackedDate
-				
-				// Check if dates are exactly one day apart
-				expectedDate := prevDate.AddDate(0, 0, 1)
-				if currentDate.Format("2006-01-02") == expectedDate.Format("2006-01-02") {
-					// Consecutive day, continue streak
-					tempStreak++
-				} else {
-					// Gap in dates, start new streak
-					tempStreak = 1
-				}
-			}
-			
-			// Update longest streak if current is longer
-			if tempStreak > longestStreak {
-				longestStreak = tempStreak
-			}
-			
-			// If this is the last entry, set current streak
-			if i == len(progressEntries)-1 {
-				// Check if the last entry is today or yesterday to count as current streak
-				today := time.Now()
-				lastDate := entry.TrackedDate
-				daysDiff := int(today.Sub(lastDate).Hours() / 24)
-				
-				if daysDiff <= 1 { // Today or yesterday
-					currentStreak = tempStreak
-				} else {
-					currentStreak = 0 // Streak was broken
-				}
-			}
-		} else {
-			// No progress, break the streak
-			tempStreak = 0
-			if i == len(progressEntries)-1 {
-				currentStreak = 0
-			}
-		}
-	}
-
-	avgCompletion := totalCompletion / float64(len(progressEntries))
-
-	return &models.ProgressSummary{
-		GoalID:            goalID,
-		GoalTitle:         goal.Title,
-		GoalType:          goal.Type,
-		TotalEntries:      len(progressEntries),
-		AverageCompletion: avgCompletion,
-		BestCompletion:    bestCompletion,
-		CurrentStreak:     currentStreak,
-		LongestStreak:     longestStreak,
-		LastTrackedDate:   progressEntries[len(progressEntries)-1].TrackedDate,
-	}, nil
-}
\ No newline at end of file
+	var lastTrackedDate time.Time
+
+	for _, day := range days {
+		if day.Date.Before(startDate) {
+			continue
+		}
+		totalCompletion += day.CompletionRate
+		if day.CompletionRate > bestCompletion {
+			bestCompletion = day.CompletionRate
+		}
+		summary.TrackedDays++
+		lastTrackedDate = day.Date
+	}
+	for _, entry := range progressEntries {
+		if !entry.TrackedDate.Before(startDate) {
+			summary.TotalEntries++
+		}
+	}
+
+	// Limit goals are judged from the day they were created, others from the first entry
+	historyStart := streaks.DateIn(goal.CreatedAt, user.Location())
+	if goal.Direction != models.GoalDirectionAtMost {
+		historyStart = streaks.DateOf(progressEntries[0].TrackedDate)
+	}
+	input := streaks.Input{
+		Rates:   make(map[streaks.Date]float64, len(days)),
+		Excused: make(map[streaks.Date]bool),
+		Start:   historyStart,
+		End:     streaks.DateOf(endDate),
+		Today:   today,
+	}
+	for _, day := range days {
+		input.Rates[streaks.DateOf(day.Date)] = day.CompletionRate
+	}
+
+	excuses, err := Excuses(v.db, &goal, historyStart.Time(), endDate)
+	if err != nil {
+		return nil, err
+	}
+	for key := range excuses {
+		if day, err := streaks.ParseDate(key); err == nil {
+			input.Excused[day] = true
+		}
+	}
+
+	definition := goal.StreakDefinition()
+	history := streaks.Compute(definition, input)
+	summary.CurrentStreak = history.Current
+	summary.LongestStreak = history.Longest
+	summary.StreakHistory = history.Intervals
+
+	// Missed and excused days only count inside the requested range
+	input.Start = max(historyStart, streaks.DateOf(startDate))
+	inRange := streaks.Compute(definition, input)
+	summary.MissedDays = inRange.Missed
+	summary.ExcusedDays = inRange.Excused
+
+	if summary.TrackedDays > 0 {
+		summary.AverageCompletion = totalCompletion / float64(summary.TrackedDays)
+		summary.BestCompletion = bestCompletion
+		summary.LastTrackedDate = lastTrackedDate
+	}
+	return summary, nil
+}
@@ -0,0 +1,118 @@
+// Package stats has the few statistics the analytics endpoints need: Pearson
+// correlation and how much to trust it.
+package stats
+
+import "math"
+
+// Pearson returns the correlation coefficient of two equally long samples, and
+// false when it is undefined: fewer than two pairs, or a sample that never varies
+func Pearson(xs, ys []float64) (float64, bool) {
+	n := len(xs)
+	if n < 2 || n != len(ys) {
+		return 0, false
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var covariance, varianceX, varianceY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+	r := covariance / math.Sqrt(varianceX*varianceY)
+	return math.Max(-1, math.Min(1, r)), true
+}
+
+// PearsonPValue is the two-sided p-value of r over n pairs against no
+// correlation, from Student's t distribution with n-2 degrees of freedom
+func PearsonPValue(r float64, n int) float64 {
+	if n < 3 {
+		return 1
+	}
+	if math.Abs(r) >= 1 {
+		return 0
+	}
+	df := float64(n - 2)
+	t2 := r * r * df / (1 - r*r)
+	return regularizedBeta(df/(df+t2), df/2, 0.5)
+}
+
+// FisherInterval is the confidence interval for r over n pairs, through
+// Fisher's z transform. z is the normal quantile, 1.96 for 95%.
+func FisherInterval(r float64, n int, z float64) (float64, float64) {
+	if n < 4 {
+		return -1, 1
+	}
+	center := math.Atanh(math.Max(-1+1e-12, math.Min(1-1e-12, r)))
+	spread := z / math.Sqrt(float64(n-3))
+	return math.Tanh(center - spread), math.Tanh(center + spread)
+}
+
+// regularizedBeta is I_x(a, b), evaluated by its continued fraction
+func regularizedBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+
+	// The fraction converges quickly only below the mean, so use the symmetry
+	// I_x(a, b) = 1 - I_{1-x}(b, a) above it
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(1-x, b, a)/b
+	}
+	return front * betaFraction(x, a, b) / a
+}
+
+// betaFraction evaluates the incomplete beta continued fraction with Lentz's method
+func betaFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	result := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, numerator := range [2]float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + numerator*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + numerator/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			result *= d * c
+		}
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPearson(t *testing.T) {
+	tests := []struct {
+		name   string
+		xs, ys []float64
+		want   float64
+		wantOK bool
+	}{
+		{"perfect", []float64{1, 2, 3, 4}, []float64{10, 20, 30, 40}, 1, true},
+		{"inverse", []float64{1, 2, 3, 4}, []float64{8, 6, 4, 2}, -1, true},
+		{"partial", []float64{1, 2, 3, 4, 5}, []float64{2, 4, 5, 4, 5}, 0.7745966692, true},
+		{"constant", []float64{1, 2, 3}, []float64{5, 5, 5}, 0, false},
+		{"one pair", []float64{1}, []float64{2}, 0, false},
+		{"uneven", []float64{1, 2, 3}, []float64{1, 2}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Pearson(tt.xs, tt.ys)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Pearson() = %v, %t, want %v, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// rFromT is the correlation over n pairs whose t statistic is t
+func rFromT(t float64, n int) float64 {
+	df := float64(n - 2)
+	return t / math.Sqrt(t*t+df)
+}
+
+func TestPearsonPValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		r         float64
+		n         int
+		want      float64
+		tolerance float64
+	}{
+		// Critical values of Student's t, two-sided
+		{"df 1 at 5%", rFromT(12.7062, 3), 3, 0.05, 1e-4},
+		{"df 10 at 5%", rFromT(2.2281, 12), 12, 0.05, 1e-4},
+		{"df 30 at 5%", rFromT(2.0423, 32), 32, 0.05, 1e-4},
+		{"df 5 at 1%", rFromT(4.0321, 7), 7, 0.01, 1e-5},
+		{"df 20 at 1%", rFromT(2.8453, 22), 22, 0.01, 1e-5},
+		{"df 60 at 0.1%", rFromT(3.4602, 62), 62, 0.001, 1e-6},
+		// Closed forms: 1 - 2/π atan(t) for df 1, 1 - t/√(2+t²) for df 2
+		{"df 1 at t 1", rFromT(1, 3), 3, 0.5, 1e-12},
+		{"df 2 at t 1", rFromT(1, 4), 4, 1 - 1/math.Sqrt(3), 1e-12},
+		{"negative r", -rFromT(2.2281, 12), 12, 0.05, 1e-4},
+		{"no correlation", 0, 20, 1, 1e-12},
+		{"r is 1", 1, 10, 0, 0},
+		{"r is -1", -1, 10, 0, 0},
+		{"too few pairs", 0.9, 2, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PearsonPValue(tt.r, tt.n); math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("PearsonPValue(%v, %d) = %v, want %v", tt.r, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegularizedBeta(t *testing.T) {
+	tests := []struct {
+		x, a, b float64
+		want    float64
+	}{
+		{0.3, 1, 1, 0.3},       // Uniform
+		{0.4, 3, 1, 0.064},     // x^a
+		{0.4, 1, 3, 1 - 0.216}, // 1 - (1-x)^b
+		{0.5, 4.5, 4.5, 0.5},   // Symmetric
+		{0.3, 2, 3, 0.3483},    // Binomial tail, below the mean
+		{0.8, 2, 3, 0.9728},    // and above it
+		{0.9, 0.5, 0.5, 2 / math.Pi * math.Asin(math.Sqrt(0.9))}, // Arcsine
+		{0, 2, 3, 0},
+		{1, 2, 3, 1},
+	}
+	for _, tt := range tests {
+		if got := regularizedBeta(tt.x, tt.a, tt.b); math.Abs(got-tt.want) > 1e-10 {
+			t.Errorf("regularizedBeta(%v, %v, %v) = %v, want %v", tt.x, tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	// With a = b = 1 the fraction is I_x / (x(1-x)) = 1/(1-x)
+	if got := betaFraction(0.25, 1, 1); math.Abs(got-4.0/3) > 1e-12 {
+		t.Errorf("betaFraction(0.25, 1, 1) = %v, want 4/3", got)
+	}
+}
+
+func TestFisherInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		r         float64
+		n         int
+		low, high float64
+	}{
+		{"moderate", 0.5, 28, 0.1560, 0.7358},
+		{"negative", -0.3, 50, -0.5338, -0.0236},
+		{"none", 0, 103, -0.1935, 0.1935},
+		{"too few pairs", 0.9, 3, -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low, high := FisherInterval(tt.r, tt.n, 1.96)
+			if math.Abs(low-tt.low) > 1e-4 || math.Abs(high-tt.high) > 1e-4 {
+				t.Errorf("FisherInterval(%v, %d) = %v, %v, want %v, %v", tt.r, tt.n, low, high, tt.low, tt.high)
+			}
+		})
+	}
+
+	// A perfect correlation stays a finite interval that reaches up to 1
+	for _, r := range []float64{1, -1} {
+		low, high := FisherInterval(r, 10, 1.96)
+		if math.IsNaN(low) || math.IsNaN(high) || low > high || math.Abs(r)-math.Max(math.Abs(low), math.Abs(high)) > 1e-9 {
+			t.Errorf("FisherInterval(%v, 10) = %v, %v", r, low, high)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package streaks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Date is a calendar day, counted from 1970-01-01. Working in whole days keeps
+// streaks clear of DST shifts and time zones: convert a moment to the user's
+// date once, with DateIn, and compare days from there.
+type Date int32
+
+const secondsPerDay = 24 * 60 * 60
+
+// DateOf is the calendar date t carries, in its own location
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay)
+}
+
+// DateIn is the calendar date of t in loc
+func DateIn(t time.Time, loc *time.Location) Date {
+	return DateOf(t.In(loc))
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return 0, err
+	}
+	return DateOf(t), nil
+}
+
+// Time is the date at midnight UTC, how dates are stored
+func (d Date) Time() time.Time {
+	return time.Unix(int64(d)*secondsPerDay, 0).UTC()
+}
+
+func (d Date) AddDays(n int) Date {
+	return d + Date(n)
+}
+
+func (d Date) Weekday() time.Weekday {
+	return time.Weekday((int(d)%7 + 7 + 4) % 7) // 1970-01-01 was a Thursday
+}
+
+func (d Date) String() string {
+	return d.Time().Format("2006-01-02")
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// periodStart is the first day of the unit holding d; weeks start on Monday
+func periodStart(d Date, unit Unit) Date {
+	switch unit {
+	case UnitWeek:
+		return d.AddDays(-((int(d.Weekday()) + 6) % 7))
+	case UnitMonth:
+		t := d.Time()
+		return DateOf(time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC))
+	}
+	return d
+}
+
+// nextPeriod is the first day of the unit after the one starting on start
+func nextPeriod(start Date, unit Unit) Date {
+	switch unit {
+	case UnitWeek:
+		return start.AddDays(7)
+	case UnitMonth:
+		return DateOf(start.Time().AddDate(0, 1, 0))
+	}
+	return start.AddDays(1)
+}
@@ -0,0 +1,130 @@
+package streaks
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+func TestDateOf(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+
+	tests := []struct {
+		name string
+		time time.Time
+		loc  *time.Location
+		want string
+	}{
+		{"utc midnight", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC), time.UTC, "2026-03-08"},
+		{"late evening west of utc", time.Date(2026, 3, 9, 3, 30, 0, 0, time.UTC), newYork, "2026-03-08"},
+		{"early morning east of utc", time.Date(2026, 3, 8, 16, 0, 0, 0, time.UTC), tokyo, "2026-03-09"},
+		{"day dst starts", time.Date(2026, 3, 8, 12, 0, 0, 0, newYork), newYork, "2026-03-08"},
+		{"23 hour day's last hour", time.Date(2026, 3, 8, 23, 59, 0, 0, newYork), newYork, "2026-03-08"},
+		{"25 hour day's last hour", time.Date(2026, 11, 1, 23, 59, 0, 0, newYork), newYork, "2026-11-01"},
+		{"leap day", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC), time.UTC, "2028-02-29"},
+		{"before the epoch", time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), time.UTC, "1969-12-31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DateIn(tt.time, tt.loc).String(); got != tt.want {
+				t.Errorf("DateIn() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateDSTDaysAreOneApart(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	// Midnight to midnight is 23 hours when DST starts and 25 when it ends;
+	// dividing hours by 24 would get one of them wrong
+	for _, day := range []time.Time{
+		time.Date(2026, 3, 7, 0, 0, 0, 0, newYork),
+		time.Date(2026, 10, 31, 0, 0, 0, 0, newYork),
+	} {
+		next := day.AddDate(0, 0, 1)
+		if diff := DateIn(next, newYork) - DateIn(day, newYork); diff != 1 {
+			t.Errorf("%s to %s is %d days, want 1", day, next, diff)
+		}
+	}
+}
+
+func TestDateWeekday(t *testing.T) {
+	property := func(offset int32) bool {
+		d := Date(offset % 200000) // About 550 years either side of 1970
+		return d.Weekday() == d.Time().Weekday()
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDateRoundTrip(t *testing.T) {
+	property := func(offset int32) bool {
+		d := Date(offset % 200000)
+		parsed, err := ParseDate(d.String())
+		return err == nil && parsed == d && DateOf(d.Time()) == d
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+
+	data, err := json.Marshal(Interval{Start: mustDate(t, "2026-10-01"), End: mustDate(t, "2026-10-03"), Length: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"start":"2026-10-01","end":"2026-10-03","length":3}`; string(data) != want {
+		t.Errorf("json = %s, want %s", data, want)
+	}
+	var decoded Interval
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Start != mustDate(t, "2026-10-01") {
+		t.Errorf("round trip = %+v, %v", decoded, err)
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	tests := []struct {
+		unit       Unit
+		date       string
+		start      string
+		nextPeriod string
+	}{
+		{UnitDay, "2026-10-14", "2026-10-14", "2026-10-15"},
+		{UnitWeek, "2026-10-12", "2026-10-12", "2026-10-19"}, // Monday
+		{UnitWeek, "2026-10-18", "2026-10-12", "2026-10-19"}, // Sunday
+		{UnitWeek, "2027-01-01", "2026-12-28", "2027-01-04"},
+		{UnitMonth, "2026-10-31", "2026-10-01", "2026-11-01"},
+		{UnitMonth, "2026-12-15", "2026-12-01", "2027-01-01"},
+		{UnitMonth, "2028-02-29", "2028-02-01", "2028-03-01"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.unit)+" "+tt.date, func(t *testing.T) {
+			start := periodStart(mustDate(t, tt.date), tt.unit)
+			if start.String() != tt.start {
+				t.Errorf("periodStart = %s, want %s", start, tt.start)
+			}
+			if next := nextPeriod(start, tt.unit); next.String() != tt.nextPeriod {
+				t.Errorf("nextPeriod = %s, want %s", next, tt.nextPeriod)
+			}
+		})
+	}
+}
+
+func mustDate(t testing.TB, value string) Date {
+	t.Helper()
+	d, err := ParseDate(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
@@ -0,0 +1,238 @@
+// Package streaks computes streaks from a goal's definition and its daily
+// completion rates: the current streak, the longest, and every run in between.
+// It knows nothing about storage; callers derive the rates and excused days.
+package streaks
+
+import "time"
+
+// Unit is what one step of a streak is
+type Unit string
+
+const (
+	UnitDay   Unit = "day"
+	UnitWeek  Unit = "week" // Monday to Sunday
+	UnitMonth Unit = "month"
+)
+
+// Direction decides how a period of several days is judged and what a day
+// without an entry counts as
+type Direction string
+
+const (
+	AtLeast Direction = "at_least" // Any good day carries a period; missing days count as 0
+	AtMost  Direction = "at_most"  // Every day has to hold; missing days count as 100
+	Range   Direction = "range"    // Every day has to hold; missing days count as 0
+)
+
+// Definition describes how a goal is judged
+type Definition struct {
+	Unit        Unit
+	Direction   Direction
+	Threshold   float64         // Lowest successful completion rate; 0 means any progress
+	AnyEntry    bool            // Every logged day succeeds, whatever its rate
+	WeeklyQuota int             // Successful days a week needs; 0 judges each unit on its own
+	Scheduled   func(Date) bool // Days progress is expected on; nil means every day
+}
+
+// Input is what the streak is computed from. Rates holds the completion rate
+// (0-100) of every day with entries. Start to End is the range walked, and
+// Today, the user's current date, is neutral until it has progress.
+type Input struct {
+	Rates   map[Date]float64
+	Excused map[Date]bool // Frozen or vacation days, neutral unless successful
+	Start   Date
+	End     Date
+	Today   Date
+}
+
+// Interval is one unbroken run. Start and End are the first and last
+// successful days, or the first and last days of the periods for weekly and
+// monthly goals; Length is counted in the goal's unit.
+type Interval struct {
+	Start  Date `json:"start"`
+	End    Date `json:"end"`
+	Length int  `json:"length"`
+}
+
+type Result struct {
+	Current   int        // Length of the run still going today, 0 when the range ends before today
+	Longest   int        // Length of the longest run
+	Missed    int        // Units that broke a streak; for quota goals, the days a week fell short by
+	Excused   int        // Units an excuse kept from counting as missed
+	Intervals []Interval // Every run in order, the current one last
+}
+
+func (d Definition) succeeds(rate float64, logged bool) bool {
+	if d.AnyEntry && logged {
+		return true
+	}
+	if d.Threshold > 0 {
+		return rate >= d.Threshold
+	}
+	return rate > 0
+}
+
+func (d Definition) missingRate() float64 {
+	if d.Direction == AtMost {
+		return 100
+	}
+	return 0
+}
+
+func (d Definition) scheduledOn(day Date) bool {
+	return d.Scheduled == nil || d.Scheduled(day)
+}
+
+// Compute walks the range and returns its streaks. Unscheduled days are
+// neutral. Goals with a weekly quota count successful days, and a finished
+// week fully inside the range that falls short of the quota (lowered by one
+// per excused day) breaks the streak. Weekly and monthly goals count periods:
+// a period takes its best logged day for at-least goals and its worst for the
+// others, and the period holding today is neutral until it is decided.
+func Compute(def Definition, in Input) Result {
+	end := in.End
+	if end > in.Today {
+		end = in.Today
+	}
+
+	t := tracker{result: Result{Intervals: []Interval{}}}
+	if end >= in.Start {
+		switch {
+		case def.WeeklyQuota > 0:
+			t.walkQuota(def, in, end)
+		case def.Unit == UnitWeek || def.Unit == UnitMonth:
+			t.walkPeriods(def, in, end)
+		default:
+			t.walkDays(def, in, end)
+		}
+	}
+
+	if end == in.Today {
+		t.result.Current = t.streak
+	}
+	return t.result
+}
+
+type tracker struct {
+	result Result
+	streak int
+}
+
+// succeed extends the running streak, opening an interval when none runs
+func (t *tracker) succeed(start, end Date) {
+	if t.streak == 0 {
+		t.result.Intervals = append(t.result.Intervals, Interval{Start: start})
+	}
+	t.streak++
+	run := &t.result.Intervals[len(t.result.Intervals)-1]
+	run.End = end
+	run.Length = t.streak
+	t.result.Longest = max(t.result.Longest, t.streak)
+}
+
+func (t *tracker) fail(missed int) {
+	t.streak = 0
+	t.result.Missed += missed
+}
+
+func (t *tracker) walkDays(def Definition, in Input, end Date) {
+	for day := in.Start; day <= end; day++ {
+		if !def.scheduledOn(day) {
+			continue
+		}
+		rate, logged := in.Rates[day]
+		if !logged {
+			rate = def.missingRate()
+		}
+		success := def.succeeds(rate, logged)
+		if !logged && day == in.Today {
+			// The day isn't over yet, so an empty today is neutral
+			continue
+		}
+		switch {
+		case success:
+			t.succeed(day, day)
+		case in.Excused[day]:
+			t.result.Excused++
+		default:
+			t.fail(1)
+		}
+	}
+}
+
+func (t *tracker) walkQuota(def Definition, in Input, end Date) {
+	completed, excused := 0, 0
+	fullWeek := false
+	for day := in.Start; day <= end; day++ {
+		rate, logged := in.Rates[day]
+		if !logged {
+			rate = def.missingRate()
+		}
+		success := def.succeeds(rate, logged) && (logged || day != in.Today)
+
+		if day.Weekday() == time.Monday {
+			completed, excused = 0, 0
+			fullWeek = true
+		}
+		if success {
+			t.succeed(day, day)
+			completed++
+		} else if in.Excused[day] {
+			excused++
+		}
+		// Only weeks that are over and fully inside the range are judged
+		required := def.WeeklyQuota - excused
+		if day.Weekday() == time.Sunday && day != in.Today && fullWeek {
+			if completed < required {
+				t.fail(required - completed)
+			}
+			t.result.Excused += max(min(excused, def.WeeklyQuota-completed), 0)
+		}
+	}
+}
+
+func (t *tracker) walkPeriods(def Definition, in Input, end Date) {
+	strictest := def.Direction == AtMost || def.Direction == Range
+	for start := periodStart(in.Start, def.Unit); start <= end; start = nextPeriod(start, def.Unit) {
+		last := nextPeriod(start, def.Unit).AddDays(-1)
+		logged, excused, scheduled := false, false, false
+		var rate float64
+		for day := max(start, in.Start); day <= min(last, end); day++ {
+			if def.scheduledOn(day) {
+				scheduled = true
+			}
+			if in.Excused[day] {
+				excused = true
+			}
+			dayRate, ok := in.Rates[day]
+			if !ok {
+				continue
+			}
+			if !logged || (strictest && dayRate < rate) || (!strictest && dayRate > rate) {
+				rate = dayRate
+			}
+			logged = true
+		}
+		if !scheduled {
+			continue
+		}
+		if !logged {
+			rate = def.missingRate()
+		}
+
+		// At-least periods can still be saved until they end; a limit or
+		// range broken on any day can't
+		inProgress := in.Today <= last
+		undecided := inProgress && (!strictest || !logged || def.succeeds(rate, logged))
+		switch {
+		case def.succeeds(rate, logged) && (logged || !inProgress):
+			t.succeed(start, last)
+		case undecided:
+			// Neutral: not over yet
+		case excused:
+			t.result.Excused++
+		default:
+			t.fail(1)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package streaks
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// history builds an input from one character per day, from start:
+//
+//	x  logged at 100
+//	h  logged at 50
+//	.  logged at 0
+//	_  nothing logged
+//	e  nothing logged, excused
+//	E  logged at 100, excused
+//
+// The range ends on the last day, and today is that day plus todayOffset.
+func history(t testing.TB, start, days string, todayOffset int) Input {
+	t.Helper()
+	in := Input{
+		Rates:   map[Date]float64{},
+		Excused: map[Date]bool{},
+		Start:   mustDate(t, start),
+	}
+	for i, c := range days {
+		day := in.Start.AddDays(i)
+		switch c {
+		case 'x':
+			in.Rates[day] = 100
+		case 'h':
+			in.Rates[day] = 50
+		case '.':
+			in.Rates[day] = 0
+		case 'e':
+			in.Excused[day] = true
+		case 'E':
+			in.Rates[day] = 100
+			in.Excused[day] = true
+		case '_':
+		default:
+			t.Fatalf("unknown day %q", c)
+		}
+	}
+	in.End = in.Start.AddDays(len(days) - 1)
+	in.Today = in.End.AddDays(todayOffset)
+	return in
+}
+
+func weekdays(days ...time.Weekday) func(Date) bool {
+	return func(d Date) bool {
+		for _, day := range days {
+			if d.Weekday() == day {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestCompute(t *testing.T) {
+	daily := Definition{Unit: UnitDay, Direction: AtLeast}
+	limit := Definition{Unit: UnitDay, Direction: AtMost, Threshold: 100}
+	rangeGoal := Definition{Unit: UnitDay, Direction: Range, Threshold: 100}
+	rating := Definition{Unit: UnitDay, Direction: AtLeast, AnyEntry: true}
+
+	// 2026-10-05 is a Monday
+	tests := []struct {
+		name      string
+		def       Definition
+		in        Input
+		current   int
+		longest   int
+		missed    int
+		intervals []string // "start..end/length"
+	}{
+		{
+			name: "every day through today", def: daily,
+			in:      history(t, "2026-10-05", "xxxxx", 0),
+			current: 5, longest: 5,
+			intervals: []string{"2026-10-05..2026-10-09/5"},
+		},
+		{
+			name: "a gap breaks the streak", def: daily,
+			in:      history(t, "2026-10-05", "xx_xx", 0),
+			current: 2, longest: 2, missed: 1,
+			intervals: []string{"2026-10-05..2026-10-06/2", "2026-10-08..2026-10-09/2"},
+		},
+		{
+			name: "an empty today is neutral", def: daily,
+			in:      history(t, "2026-10-05", "xxx_", 0),
+			current: 3, longest: 3,
+			intervals: []string{"2026-10-05..2026-10-07/3"},
+		},
+		{
+			name: "a failed entry today breaks", def: daily,
+			in:      history(t, "2026-10-05", "xxx.", 0),
+			current: 0, longest: 3, missed: 1,
+			intervals: []string{"2026-10-05..2026-10-07/3"},
+		},
+		{
+			name: "any progress counts for at-least goals", def: daily,
+			in:      history(t, "2026-10-05", "xhx", 0),
+			current: 3, longest: 3,
+			intervals: []string{"2026-10-05..2026-10-07/3"},
+		},
+		{
+			name: "any logged rating counts, the lowest included", def: rating,
+			in:      history(t, "2026-10-05", "x.h_.", 0),
+			current: 1, longest: 3, missed: 1,
+			intervals: []string{"2026-10-05..2026-10-07/3", "2026-10-09..2026-10-09/1"},
+		},
+		{
+			name: "a range ending before today has no current streak", def: daily,
+			in:      history(t, "2026-10-05", "xxx", 5),
+			current: 0, longest: 3,
+			intervals: []string{"2026-10-05..2026-10-07/3"},
+		},
+		{
+			name:    "rest days are neutral",
+			def:     Definition{Unit: UnitDay, Direction: AtLeast, Scheduled: weekdays(time.Monday, time.Wednesday, time.Friday)},
+			in:      history(t, "2026-10-05", "x_x_x__x", 0),
+			current: 4, longest: 4,
+			intervals: []string{"2026-10-05..2026-10-12/4"},
+		},
+		{
+			name:    "a missed scheduled day breaks around rest days",
+			def:     Definition{Unit: UnitDay, Direction: AtLeast, Scheduled: weekdays(time.Monday, time.Wednesday, time.Friday)},
+			in:      history(t, "2026-10-05", "x_____x", 0),
+			current: 0, longest: 1, missed: 2,
+			intervals: []string{"2026-10-05..2026-10-05/1"},
+		},
+		{
+			name: "excused days are neutral", def: daily,
+			in:      history(t, "2026-10-05", "xxeexx", 0),
+			current: 4, longest: 4,
+			intervals: []string{"2026-10-05..2026-10-10/4"},
+		},
+		{
+			name: "successful excused days still count", def: daily,
+			in:      history(t, "2026-10-05", "xEx", 0),
+			current: 3, longest: 3,
+			intervals: []string{"2026-10-05..2026-10-07/3"},
+		},
+		{
+			name: "limits hold on days without entries", def: limit,
+			in:      history(t, "2026-10-05", "___", 1),
+			current: 0, longest: 3,
+			intervals: []string{"2026-10-05..2026-10-07/3"},
+		},
+		{
+			name: "an empty today is neutral for limits too", def: limit,
+			in:      history(t, "2026-10-05", "__._", 0),
+			current: 0, longest: 2, missed: 1,
+			intervals: []string{"2026-10-05..2026-10-06/2"},
+		},
+		{
+			name: "range goals need the threshold", def: rangeGoal,
+			in:      history(t, "2026-10-05", "xxhx", 0),
+			current: 1, longest: 2, missed: 1,
+			intervals: []string{"2026-10-05..2026-10-06/2", "2026-10-08..2026-10-08/1"},
+		},
+		{
+			name: "range goals fail on days without entries", def: rangeGoal,
+			in:      history(t, "2026-10-05", "x_x", 0),
+			current: 1, longest: 1, missed: 1,
+			intervals: []string{"2026-10-05..2026-10-05/1", "2026-10-07..2026-10-07/1"},
+		},
+		{
+			name:    "a week short of its quota breaks the streak",
+			def:     Definition{Unit: UnitDay, Direction: AtLeast, WeeklyQuota: 3},
+			in:      history(t, "2026-10-05", "x_x_x__"+"x______"+"_", 0),
+			current: 0, longest: 4, missed: 2,
+			intervals: []string{"2026-10-05..2026-10-12/4"},
+		},
+		{
+			name:    "excused days lower the quota",
+			def:     Definition{Unit: UnitDay, Direction: AtLeast, WeeklyQuota: 3},
+			in:      history(t, "2026-10-05", "x_xe___"+"_", 0),
+			current: 2, longest: 2,
+			intervals: []string{"2026-10-05..2026-10-07/2"},
+		},
+		{
+			name:    "a partial first week isn't judged",
+			def:     Definition{Unit: UnitDay, Direction: AtLeast, WeeklyQuota: 3},
+			in:      history(t, "2026-10-07", "x____"+"x", 0),
+			current: 2, longest: 2,
+			intervals: []string{"2026-10-07..2026-10-12/2"},
+		},
+		{
+			name:    "the current week isn't judged before it ends",
+			def:     Definition{Unit: UnitDay, Direction: AtLeast, WeeklyQuota: 3},
+			in:      history(t, "2026-10-05", "x______", 0),
+			current: 1, longest: 1,
+			intervals: []string{"2026-10-05..2026-10-05/1"},
+		},
+		{
+			name:    "weekly goals count weeks",
+			def:     Definition{Unit: UnitWeek, Direction: AtLeast},
+			in:      history(t, "2026-09-07", "x______"+"__.h___"+"_______"+"x______"+"_______"+"_x____", 0),
+			current: 1, longest: 2, missed: 2,
+			intervals: []string{"2026-09-07..2026-09-20/2", "2026-09-28..2026-10-04/1", "2026-10-12..2026-10-18/1"},
+		},
+		{
+			name:    "the current week is neutral until it succeeds",
+			def:     Definition{Unit: UnitWeek, Direction: AtLeast},
+			in:      history(t, "2026-09-28", "x______"+"___", 0),
+			current: 1, longest: 1,
+			intervals: []string{"2026-09-28..2026-10-04/1"},
+		},
+		{
+			name:    "an excused week is neutral",
+			def:     Definition{Unit: UnitWeek, Direction: AtLeast},
+			in:      history(t, "2026-09-28", "x______"+"__e____"+"x", 0),
+			current: 2, longest: 2,
+			intervals: []string{"2026-09-28..2026-10-18/2"},
+		},
+		{
+			name:    "monthly limits are judged on their worst day",
+			def:     Definition{Unit: UnitMonth, Direction: AtMost, Threshold: 100},
+			in:      history(t, "2026-08-01", "x"+"______________________________"+"x____h", 0),
+			current: 0, longest: 1, missed: 1,
+			intervals: []string{"2026-08-01..2026-08-31/1"},
+		},
+		{
+			name:    "monthly goals count months",
+			def:     Definition{Unit: UnitMonth, Direction: AtLeast},
+			in:      history(t, "2026-08-01", "x______________________________"+"__x", 0),
+			current: 2, longest: 2,
+			intervals: []string{"2026-08-01..2026-09-30/2"},
+		},
+		{
+			name: "an empty range", def: daily,
+			in:        Input{Start: mustDate(t, "2026-10-05"), End: mustDate(t, "2026-10-04"), Today: mustDate(t, "2026-10-05")},
+			intervals: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(tt.def, tt.in)
+			if got.Current != tt.current || got.Longest != tt.longest || got.Missed != tt.missed {
+				t.Errorf("current, longest, missed = %d, %d, %d, want %d, %d, %d",
+					got.Current, got.Longest, got.Missed, tt.current, tt.longest, tt.missed)
+			}
+			intervals := []string{}
+			for _, run := range got.Intervals {
+				intervals = append(intervals, run.Start.String()+".."+run.End.String()+"/"+strconv.Itoa(run.Length))
+			}
+			if !reflect.DeepEqual(intervals, tt.intervals) {
+				t.Errorf("intervals = %v, want %v", intervals, tt.intervals)
+			}
+		})
+	}
+}
+
+func TestComputeCountsExcusedMisses(t *testing.T) {
+	daily := Definition{Unit: UnitDay, Direction: AtLeast}
+
+	// 2026-10-05 is a Monday
+	tests := []struct {
+		name string
+		def  Definition
+		in   Input
+		want int
+	}{
+		{"excused days without success", daily, history(t, "2026-10-05", "xeEex", 0), 2},
+		{"excused unscheduled days", Definition{Unit: UnitDay, Direction: AtLeast, Scheduled: weekdays(time.Monday, time.Wednesday)},
+			history(t, "2026-10-05", "xexe_e", 0), 0},
+		{"an excused empty today", daily, history(t, "2026-10-05", "xxe", 0), 0},
+		{"excuses beyond a week's shortfall", Definition{Unit: UnitDay, Direction: AtLeast, WeeklyQuota: 3},
+			history(t, "2026-10-05", "xeexeee", 1), 1},
+		{"an excused week", Definition{Unit: UnitWeek, Direction: AtLeast}, history(t, "2026-10-05", "eeeeeee", 1), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compute(tt.def, tt.in).Excused; got != tt.want {
+				t.Errorf("excused = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// randomCase is a random definition and history for property tests
+type randomCase struct {
+	Def Definition
+	In  Input
+}
+
+func (randomCase) Generate(r *rand.Rand, size int) reflect.Value {
+	c := randomCase{
+		Def: Definition{
+			Unit:      []Unit{UnitDay, UnitDay, UnitWeek, UnitMonth}[r.Intn(4)],
+			Direction: []Direction{AtLeast, AtMost, Range}[r.Intn(3)],
+		},
+		In: Input{
+			Rates:   map[Date]float64{},
+			Excused: map[Date]bool{},
+			Start:   DateOf(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)).AddDays(r.Intn(400) - 200),
+		},
+	}
+	if c.Def.Direction != AtLeast {
+		c.Def.Threshold = 100
+	}
+	if c.Def.Unit == UnitDay && r.Intn(4) == 0 {
+		c.Def.WeeklyQuota = 1 + r.Intn(5)
+	}
+	if r.Intn(3) == 0 {
+		mask := 1 + r.Intn(127)
+		c.Def.Scheduled = func(d Date) bool { return mask&(1<<d.Weekday()) != 0 }
+	}
+
+	days := r.Intn(size*3 + 1)
+	for i := 0; i < days; i++ {
+		day := c.In.Start.AddDays(i)
+		switch r.Intn(5) {
+		case 0:
+			c.In.Rates[day] = 0
+		case 1:
+			c.In.Rates[day] = 50
+		case 2, 3:
+			c.In.Rates[day] = 100
+		}
+		if r.Intn(10) == 0 {
+			c.In.Excused[day] = true
+		}
+	}
+	c.In.End = c.In.Start.AddDays(days - 1)
+	c.In.Today = c.In.End.AddDays(r.Intn(3) - 1)
+	return reflect.ValueOf(c)
+}
+
+// clone copies the input so a property can change one without the other
+func (c randomCase) clone() randomCase {
+	out := c
+	out.In.Rates = make(map[Date]float64, len(c.In.Rates))
+	for day, rate := range c.In.Rates {
+		out.In.Rates[day] = rate
+	}
+	out.In.Excused = make(map[Date]bool, len(c.In.Excused))
+	for day := range c.In.Excused {
+		out.In.Excused[day] = true
+	}
+	return out
+}
+
+func TestComputeIntervalsAreConsistent(t *testing.T) {
+	property := func(c randomCase) bool {
+		got := Compute(c.Def, c.In)
+		longest := 0
+		for i, run := range got.Intervals {
+			if run.Length < 1 || run.End < run.Start {
+				return false
+			}
+			if i > 0 && got.Intervals[i-1].End >= run.Start {
+				return false
+			}
+			longest = max(longest, run.Length)
+		}
+		if got.Longest != longest || got.Current > got.Longest || got.Missed < 0 {
+			return false
+		}
+		// The current streak is the last run
+		return got.Current == 0 || got.Intervals[len(got.Intervals)-1].Length == got.Current
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComputeStaysInRange(t *testing.T) {
+	property := func(c randomCase) bool {
+		got := Compute(c.Def, c.In)
+		last := min(c.In.End, c.In.Today)
+		for _, run := range got.Intervals {
+			// Periods may reach outside the range, but always hold a day of it
+			if run.Start > last || run.End < c.In.Start {
+				return false
+			}
+			if c.Def.Unit == UnitDay && (run.Start < c.In.Start || run.End > last) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComputeSuccessNeverHurts(t *testing.T) {
+	property := func(c randomCase, pick uint16) bool {
+		if c.Def.Unit != UnitDay || c.Def.WeeklyQuota > 0 || c.In.End < c.In.Start {
+			return true
+		}
+		day := c.In.Start.AddDays(int(pick) % (int(c.In.End-c.In.Start) + 1))
+		better := c.clone()
+		better.In.Rates[day] = 100
+
+		before, after := Compute(c.Def, c.In), Compute(better.Def, better.In)
+		return after.Longest >= before.Longest && after.Current >= before.Current && after.Missed <= before.Missed
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComputeExcusingNeverHurts(t *testing.T) {
+	property := func(c randomCase, pick uint16) bool {
+		if c.In.End < c.In.Start {
+			return true
+		}
+		day := c.In.Start.AddDays(int(pick) % (int(c.In.End-c.In.Start) + 1))
+		excused := c.clone()
+		excused.In.Excused[day] = true
+
+		before, after := Compute(c.Def, c.In), Compute(excused.Def, excused.In)
+		return after.Longest >= before.Longest && after.Current >= before.Current && after.Missed <= before.Missed
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComputeShiftsWithWeeks(t *testing.T) {
+	// Moving a history by whole weeks keeps weekdays, so only the dates change
+	property := func(c randomCase, weeks int8) bool {
+		if c.Def.Unit == UnitMonth {
+			return true
+		}
+		shift := int(weeks) * 7
+		moved := randomCase{Def: c.Def, In: Input{
+			Rates:   map[Date]float64{},
+			Excused: map[Date]bool{},
+			Start:   c.In.Start.AddDays(shift),
+			End:     c.In.End.AddDays(shift),
+			Today:   c.In.Today.AddDays(shift),
+		}}
+		for day, rate := range c.In.Rates {
+			moved.In.Rates[day.AddDays(shift)] = rate
+		}
+		for day := range c.In.Excused {
+			moved.In.Excused[day.AddDays(shift)] = true
+		}
+
+		before, after := Compute(c.Def, c.In), Compute(moved.Def, moved.In)
+		if before.Current != after.Current || before.Longest != after.Longest || before.Missed != after.Missed ||
+			len(before.Intervals) != len(after.Intervals) {
+			return false
+		}
+		for i := range before.Intervals {
+			if after.Intervals[i].Start != before.Intervals[i].Start.AddDays(shift) || after.Intervals[i].Length != before.Intervals[i].Length {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComputeDailyStreaksCountDays(t *testing.T) {
+	// A daily streak is made of successful, scheduled days only
+	property := func(c randomCase) bool {
+		if c.Def.Unit != UnitDay || c.Def.WeeklyQuota > 0 || c.Def.Direction == AtMost {
+			return true
+		}
+		got := Compute(c.Def, c.In)
+		for _, run := range got.Intervals {
+			successes := 0
+			for day := run.Start; day <= run.End; day++ {
+				if rate, ok := c.In.Rates[day]; ok && c.Def.succeeds(rate, ok) && c.Def.scheduledOn(day) {
+					successes++
+				}
+			}
+			if successes != run.Length {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package templates holds the built-in goal templates and template packs.
+package templates
+
+import (
+	"time"
+
+	"github.com/tarikozturk017/streak-map/backend/internal/models"
+)
+
+var builtins = []models.TemplateGoal{
+	{
+		Key:             "reading",
+		Title:           "Read every day",
+		Description:     "A few pages a day adds up to a lot of books.",
+		Type:            models.GoalTypeQuantity,
+		ColorCode:       "#4F46E5",
+		Target:          20,
+		Unit:            "pages",
+		ScheduleRequest: daily(),
+	},
+	{
+		Key:             "exercise",
+		Title:           "Work out",
+		Description:     "Half an hour of training, three times a week.",
+		Type:            models.GoalTypeTime,
+		ColorCode:       "#EF4444",
+		Target:          30,
+		Unit:            "minutes",
+		ScheduleRequest: weekdays(time.Monday, time.Wednesday, time.Friday),
+	},
+	{
+		Key:             "running",
+		Title:           "Go for a run",
+		Description:     "Three runs a week, on whichever days suit you.",
+		Type:            models.GoalTypeDistance,
+		ColorCode:       "#F97316",
+		Target:          5,
+		Unit:            "km",
+		ScheduleRequest: timesPerWeek(3),
+	},
+	{
+		Key:             "meditation",
+		Title:           "Meditate",
+		Description:     "Ten quiet minutes a day.",
+		Type:            models.GoalTypeTime,
+		ColorCode:       "#10B981",
+		Target:          10,
+		Unit:            "minutes",
+		ScheduleRequest: daily(),
+	},
+	{
+		Key:             "language-study",
+		Title:           "Study a language",
+		Description:     "Lessons, flashcards or conversation practice.",
+		Type:            models.GoalTypeTime,
+		ColorCode:       "#F59E0B",
+		Target:          20,
+		Unit:            "minutes",
+		ScheduleRequest: daily(),
+	},
+	{
+		Key:             "vocabulary",
+		Title:           "Learn new words",
+		Description:     "New vocabulary, reviewed the same day.",
+		Type:            models.GoalTypeQuantity,
+		ColorCode:       "#EAB308",
+		Target:          10,
+		Unit:            "words",
+		ScheduleRequest: daily(),
+	},
+}
+
+var packs = []models.TemplatePack{
+	{
+		Key:         "wellness",
+		Name:        "Wellness",
+		Description: "Move, rest the mind and keep reading.",
+		ColorCode:   "#10B981",
+		Goals:       mustGet("exercise", "meditation", "reading"),
+	},
+	{
+		Key:         "language-learning",
+		Name:        "Language learning",
+		Description: "Daily study time plus new vocabulary.",
+		ColorCode:   "#F59E0B",
+		Goals:       mustGet("language-study", "vocabulary"),
+	},
+}
+
+// Builtins returns the built-in templates
+func Builtins() []models.TemplateGoal {
+	return append([]models.TemplateGoal(nil), builtins...)
+}
+
+// Packs returns the built-in template packs
+func Packs() []models.TemplatePack {
+	return append([]models.TemplatePack(nil), packs...)
+}
+
+func Lookup(key string) (models.TemplateGoal, bool) {
+	for _, template := range builtins {
+		if template.Key == key {
+			return template, true
+		}
+	}
+	return models.TemplateGoal{}, false
+}
+
+func LookupPack(key string) (models.TemplatePack, bool) {
+	for _, pack := range packs {
+		if pack.Key == key {
+			return pack, true
+		}
+	}
+	return models.TemplatePack{}, false
+}
+
+func mustGet(keys ...string) []models.TemplateGoal {
+	goals := make([]models.TemplateGoal, 0, len(keys))
+	for _, key := range keys {
+		template, ok := Lookup(key)
+		if !ok {
+			panic("templates: unknown built-in template " + key)
+		}
+		goals = append(goals, template)
+	}
+	return goals
+}
+
+func daily() models.ScheduleRequest {
+	scheduleType := models.ScheduleTypeDaily
+	return models.ScheduleRequest{ScheduleType: &scheduleType}
+}
+
+func weekdays(days ...time.Weekday) models.ScheduleRequest {
+	scheduleType := models.ScheduleTypeWeekdays
+	set := models.NewWeekdaySet(days...)
+	return models.ScheduleRequest{ScheduleType: &scheduleType, ScheduleWeekdays: &set}
+}
+
+func timesPerWeek(n int) models.ScheduleRequest {
+	scheduleType := models.ScheduleTypeTimesPerWeek
+	return models.ScheduleRequest{ScheduleType: &scheduleType, ScheduleTimesPerWeek: &n}
+}
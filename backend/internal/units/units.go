@@ -0,0 +1,163 @@
+// Package units knows the measurement units goals can be tracked in, how they
+// relate to each other and how to render values for a preferred unit system.
+// Every dimension has a base unit that values are stored in: minutes for time
+// and meters for distance.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+type Dimension string
+
+const (
+	DimensionNone     Dimension = ""         // Free-form labels (pages, pushups) that don't convert
+	DimensionTime     Dimension = "time"     // Base unit: minutes
+	DimensionDistance Dimension = "distance" // Base unit: meters
+)
+
+type System string
+
+const (
+	SystemMetric   System = "metric"
+	SystemImperial System = "imperial"
+)
+
+func (s System) IsValid() bool {
+	return s == SystemMetric || s == SystemImperial
+}
+
+type Unit struct {
+	Name      string // Canonical name, as stored on goals
+	Symbol    string // Short form used when rendering values
+	Dimension Dimension
+	ToBase    float64 // Multiply by this to get the base unit
+	System    System  // Empty for units shared by both systems
+}
+
+var (
+	Minutes    = Unit{Name: "minutes", Symbol: "m", Dimension: DimensionTime, ToBase: 1}
+	Hours      = Unit{Name: "hours", Symbol: "h", Dimension: DimensionTime, ToBase: 60}
+	Seconds    = Unit{Name: "seconds", Symbol: "s", Dimension: DimensionTime, ToBase: 1.0 / 60}
+	Meters     = Unit{Name: "meters", Symbol: "m", Dimension: DimensionDistance, ToBase: 1, System: SystemMetric}
+	Kilometers = Unit{Name: "km", Symbol: "km", Dimension: DimensionDistance, ToBase: 1000, System: SystemMetric}
+	Miles      = Unit{Name: "miles", Symbol: "mi", Dimension: DimensionDistance, ToBase: 1609.344, System: SystemImperial}
+	Yards      = Unit{Name: "yards", Symbol: "yd", Dimension: DimensionDistance, ToBase: 0.9144, System: SystemImperial}
+	Feet       = Unit{Name: "feet", Symbol: "ft", Dimension: DimensionDistance, ToBase: 0.3048, System: SystemImperial}
+)
+
+var ErrUnknownUnit = errors.New("unknown unit")
+
+// aliases maps every accepted spelling to its unit
+var aliases = map[string]Unit{}
+
+func init() {
+	register(Seconds, "s", "sec", "secs", "second")
+	register(Minutes, "m", "min", "mins", "minute")
+	register(Hours, "h", "hr", "hrs", "hour")
+	register(Meters, "meter", "metre", "metres")
+	register(Kilometers, "kilometer", "kilometers", "kilometre", "kilometres", "kms")
+	register(Miles, "mi", "mile")
+	register(Yards, "yd", "yard")
+	register(Feet, "ft", "foot")
+}
+
+func register(u Unit, names ...string) {
+	aliases[u.Name] = u
+	for _, name := range names {
+		aliases[name] = u
+	}
+}
+
+// Lookup finds a unit by name, symbol or common spelling, ignoring case.
+// Note that "m" is minutes; meters must be spelled out.
+func Lookup(name string) (Unit, error) {
+	if u, ok := aliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return u, nil
+	}
+	return Unit{}, fmt.Errorf("%w %q", ErrUnknownUnit, name)
+}
+
+// Base returns the unit values of a dimension are stored in
+func Base(d Dimension) (Unit, bool) {
+	switch d {
+	case DimensionTime:
+		return Minutes, true
+	case DimensionDistance:
+		return Meters, true
+	}
+	return Unit{}, false
+}
+
+// ToBase converts a value in the named unit into the base unit of the expected dimension
+func ToBase(value float64, unit string, d Dimension) (float64, error) {
+	u, err := Lookup(unit)
+	if err != nil {
+		return 0, err
+	}
+	if u.Dimension != d {
+		return 0, fmt.Errorf("unit %q is not a %s unit", unit, d)
+	}
+	return value * u.ToBase, nil
+}
+
+// FromBase converts a base-unit value into the named unit
+func FromBase(value float64, unit string) (float64, error) {
+	u, err := Lookup(unit)
+	if err != nil {
+		return 0, err
+	}
+	return value / u.ToBase, nil
+}
+
+// Convert converts a value between two units of the same dimension
+func Convert(value float64, from, to string) (float64, error) {
+	f, err := Lookup(from)
+	if err != nil {
+		return 0, err
+	}
+	t, err := Lookup(to)
+	if err != nil {
+		return 0, err
+	}
+	if f.Dimension != t.Dimension {
+		return 0, fmt.Errorf("cannot convert %s to %s", f.Name, t.Name)
+	}
+	return value * f.ToBase / t.ToBase, nil
+}
+
+// Format renders a base-unit value for the given unit system, picking a unit
+// that suits its magnitude (e.g., 800 meters, 5.2 km, 3.1 mi)
+func Format(value float64, d Dimension, system System) string {
+	switch d {
+	case DimensionTime:
+		return FormatDuration(value)
+	case DimensionDistance:
+		if system == SystemImperial {
+			if miles := value / Miles.ToBase; math.Abs(miles) >= 0.1 {
+				return fmt.Sprintf("%.1f %s", miles, Miles.Symbol)
+			}
+			return fmt.Sprintf("%.0f %s", value/Feet.ToBase, Feet.Symbol)
+		}
+		if math.Abs(value) >= Kilometers.ToBase {
+			return fmt.Sprintf("%.1f %s", value/Kilometers.ToBase, Kilometers.Symbol)
+		}
+		return fmt.Sprintf("%.0f %s", value, Meters.Symbol)
+	}
+	return fmt.Sprintf("%.1f", value)
+}
+
+// FormatDuration renders minutes as "2h 30m", "2h" or "45m"
+func FormatDuration(minutes float64) string {
+	hours := int(minutes) / 60
+	mins := int(minutes) % 60
+	if hours > 0 && mins > 0 {
+		return fmt.Sprintf("%dh %dm", hours, mins)
+	} else if hours > 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dm", mins)
+}